@@ -0,0 +1,15 @@
+package main
+
+import (
+	"os"
+
+	"github.com/rc1405/go-event-bus-gen/internal/generator"
+	"github.com/rs/zerolog/log"
+)
+
+// protoc-gen-go-event-bus generates the event bus as a protoc plugin, i.e. for --go-event-bus_out or buf generate.
+func main() {
+	if err := generator.RunPlugin(os.Stdin, os.Stdout, os.Stderr); err != nil {
+		log.Fatal().Err(err).Msg("failed running code generation")
+	}
+}
@@ -24,7 +24,7 @@ type Handler struct {
 	ec2Client Ec2Client
 }
 
-func (h *Handler) ParseEvent(event events.CloudWatchEvent) (Finding, error) {
+func (h *Handler) ParseEvent(ctx context.Context, event events.CloudWatchEvent) (Finding, error) {
 	var finding Finding
 	if err := json.Unmarshal(event.Detail, &finding); err != nil {
 		return Finding{}, err
@@ -33,14 +33,14 @@ func (h *Handler) ParseEvent(event events.CloudWatchEvent) (Finding, error) {
 	return finding, nil
 }
 
-func (h *Handler) Evaluate(finding Finding) error {
+func (h *Handler) Evaluate(ctx context.Context, finding Finding) error {
 	switch {
 	case finding.Resource.AccessKeyDetails.UserType == "IAMUser" && finding.Resource.ResourceType == "AccessKey":
-		if err := h.bus.Publish(finding.Resource.AccessKeyDetails); err != nil {
+		if err := h.bus.PublishContext(ctx, finding.Resource.AccessKeyDetails); err != nil {
 			return err
 		}
 	case finding.Resource.ResourceType == "Instance":
-		if err := h.bus.Publish(InstanceDetails{
+		if err := h.bus.PublishContext(ctx, InstanceDetails{
 			Region:     finding.Region,
 			InstanceId: finding.Resource.InstanceDetails.InstanceId,
 		}); err != nil {
@@ -51,8 +51,8 @@ func (h *Handler) Evaluate(finding Finding) error {
 	return nil
 }
 
-func (h *Handler) DisableAccessKey(key AccessKeyDetails) error {
-	_, err := h.iamClient.UpdateAccessKey(context.Background(), &iam.UpdateAccessKeyInput{
+func (h *Handler) DisableAccessKey(ctx context.Context, key AccessKeyDetails) error {
+	_, err := h.iamClient.UpdateAccessKey(ctx, &iam.UpdateAccessKeyInput{
 		AccessKeyId: &key.AccessKeyId,
 		Status:      types.StatusTypeInactive,
 		UserName:    &key.UserName,
@@ -63,8 +63,8 @@ func (h *Handler) DisableAccessKey(key AccessKeyDetails) error {
 	return err
 }
 
-func (h *Handler) StopInstance(details InstanceDetails) error {
-	_, err := h.ec2Client.StopInstances(context.Background(), &ec2.StopInstancesInput{
+func (h *Handler) StopInstance(ctx context.Context, details InstanceDetails) error {
+	_, err := h.ec2Client.StopInstances(ctx, &ec2.StopInstancesInput{
 		InstanceIds: []string{details.InstanceId},
 	}, func(opt *ec2.Options) {
 		opt.Region = details.Region
@@ -2,7 +2,11 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -25,7 +29,35 @@ func HandleRequest(ctx context.Context, event *events.CloudWatchEvent) error {
 }
 
 func main() {
-	bus := NewEventBus()
+	record := flag.String("record", "", "append every published event to this file")
+	replay := flag.String("replay", "", "replay the events recorded in this file instead of handling Lambda invocations")
+	speed := flag.Float64("speed", 0, "speed of the replay relative to the recording, 0 replays without waiting")
+	types := flag.String("types", "", "comma separated event types to replay, all when empty")
+	dedup := flag.String("dedup", "", "remember the handled events in this file instead of in memory")
+	flag.Parse()
+
+	opts := []func(*Options){
+		// duplicates are only skipped within one execution environment, see Duplicate Deliveries in the README
+		func(o *Options) {
+			o.Dedup = &DedupOptions{Window: time.Hour}
+			if *dedup != "" {
+				o.Dedup.Store = NewFileDedupStore(*dedup, 10000)
+			}
+		},
+	}
+	if *record != "" {
+		fout, err := os.OpenFile(*record, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			panic(err)
+		}
+		defer fout.Close()
+
+		recorder := NewRecorder(fout)
+		opts = append(opts, func(o *Options) {
+			o.PublishInterceptors = append(o.PublishInterceptors, recorder.Intercept)
+		})
+	}
+	bus = NewEventBus(opts...)
 
 	cfg, err := config.LoadDefaultConfig(context.Background())
 	if err != nil {
@@ -38,8 +70,48 @@ func main() {
 		ec2Client: ec2.NewFromConfig(cfg),
 	}
 
+	if *replay != "" {
+		if err := replayFile(&svc, *replay, *speed, *types); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	go bus.Run(context.Background(), &svc)
 	bus.Ready()
 
 	lambda.Start(HandleRequest)
 }
+
+// replayFile feeds a recorded incident back into the handlers, waiting for them to finish
+func replayFile(svc *Handler, path string, speed float64, types string) error {
+	fin, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fin.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- bus.Run(ctx, svc)
+	}()
+	bus.Ready()
+
+	err = bus.Replay(ctx, fin, func(o *ReplayOptions) {
+		o.Speed = speed
+		if types != "" {
+			o.Types = strings.Split(types, ",")
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := bus.Shutdown(ctx); err != nil {
+		return err
+	}
+	return <-errs
+}
@@ -114,6 +114,89 @@ func (suite *EventBusTestSuite) TestInstance() {
 	wg.Wait()
 }
 
+func (suite *EventBusTestSuite) TestHandleRequest() {
+	suite.iamClient.EXPECT().UpdateAccessKey(gomock.Any(), &iam.UpdateAccessKeyInput{
+		AccessKeyId: aws.String("GeneratedFindingAccessKeyId"),
+		Status:      types.StatusTypeInactive,
+		UserName:    aws.String("GeneratedFindingUserName"),
+	}, gomock.Any()).Return(&iam.UpdateAccessKeyOutput{}, nil)
+
+	bus = NewEventBus()
+	handler := Handler{
+		bus:       bus,
+		iamClient: suite.iamClient,
+		ec2Client: suite.ec2Client,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := bus.Run(ctx, &handler); err != nil {
+			panic(err)
+		}
+	}()
+
+	bus.Ready()
+
+	// Lambda hands the event over as a pointer
+	err := HandleRequest(ctx, &events.CloudWatchEvent{
+		Time:       suite.now,
+		DetailType: "GuardDuty Finding",
+		Region:     "us-west-2",
+		Detail:     maliciousCaller,
+	})
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestDuplicateDelivery() {
+	suite.iamClient.EXPECT().UpdateAccessKey(gomock.Any(), &iam.UpdateAccessKeyInput{
+		AccessKeyId: aws.String("GeneratedFindingAccessKeyId"),
+		Status:      types.StatusTypeInactive,
+		UserName:    aws.String("GeneratedFindingUserName"),
+	}, gomock.Any()).Return(&iam.UpdateAccessKeyOutput{}, nil).Times(1)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Dedup = &DedupOptions{}
+	})
+	event := events.CloudWatchEvent{
+		ID:         "b8d2e9a4-7d05-4c1e-9d6f-1c2a3b4c5d6e",
+		Time:       suite.now,
+		DetailType: "GuardDuty Finding",
+		Region:     "us-west-2",
+		Detail:     maliciousCaller,
+	}
+
+	handler := Handler{
+		bus:       bus,
+		iamClient: suite.iamClient,
+		ec2Client: suite.ec2Client,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := bus.Run(ctx, &handler); err != nil {
+			panic(err)
+		}
+	}()
+
+	bus.Ready()
+
+	// EventBridge delivers the same event twice
+	assert.Nil(suite.T(), bus.Publish(event))
+	assert.Nil(suite.T(), bus.Publish(event))
+	wg.Wait()
+}
+
 func TestEventBusTestSuite(t *testing.T) {
 	suite.Run(t, new(EventBusTestSuite))
 }
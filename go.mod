@@ -9,6 +9,7 @@ require (
 	github.com/stretchr/testify v1.9.0
 	github.com/yoheimuta/go-protoparser/v4 v4.11.0
 	go.uber.org/mock v0.4.0
+	google.golang.org/protobuf v1.34.1
 )
 
 require (
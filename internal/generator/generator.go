@@ -0,0 +1,1063 @@
+package generator
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed codegen.tmpl
+var event_bus_tmpl string
+var rootCmd *cobra.Command
+var inFiles []string
+var protoPaths []string
+var outFile string
+var confFile string
+var withContext bool
+var withPointers bool
+var logger zerolog.Logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+
+var protoToGoTypes = map[string]string{
+	"double":   "float64",
+	"float":    "float32",
+	"int32":    "int32",
+	"int64":    "int64",
+	"uint32":   "uint32",
+	"uint64":   "uint64",
+	"sint32":   "int32",
+	"sint64":   "int64",
+	"fixed32":  "uint32",
+	"fixed64":  "uint64",
+	"sfixed32": "int32",
+	"sfixed64": "int64",
+	"bool":     "bool",
+	"string":   "string",
+	"bytes":    "[]byte",
+}
+
+type TypeOverwrite struct {
+	Name   string
+	Import string
+}
+
+var overWriteTypes = map[string]TypeOverwrite{
+	"google.protobuf.Timestamp": {
+		Name:   "time.Time",
+		Import: "time",
+	},
+	"google.protobuf.Any": {
+		Name: "any",
+	},
+	"google.protobuf.Duration": {
+		Name:   "time.Duration",
+		Import: "time",
+	},
+	"google.protobuf.Struct":      {Name: "map[string]any"},
+	"google.protobuf.Value":       {Name: "any"},
+	"google.protobuf.ListValue":   {Name: "[]any"},
+	"google.protobuf.FieldMask":   {Name: "[]string"},
+	"google.protobuf.DoubleValue": {Name: "*float64"},
+	"google.protobuf.FloatValue":  {Name: "*float32"},
+	"google.protobuf.Int64Value":  {Name: "*int64"},
+	"google.protobuf.UInt64Value": {Name: "*uint64"},
+	"google.protobuf.Int32Value":  {Name: "*int32"},
+	"google.protobuf.UInt32Value": {Name: "*uint32"},
+	"google.protobuf.BoolValue":   {Name: "*bool"},
+	"google.protobuf.StringValue": {Name: "*string"},
+	"google.protobuf.BytesValue":  {Name: "[]byte"},
+}
+
+type Attribute struct {
+	Name     string
+	Type     string
+	RawName  string
+	Optional bool
+	Repeated bool
+}
+
+// OneofCase is a field of a oneof, wrapped in a struct of its own.
+type OneofCase struct {
+	Name    string
+	Field   string
+	Type    string
+	RawName string
+}
+
+// Oneof is generated as a field whose interface type is implemented by the wrapper of each of its cases.
+type Oneof struct {
+	Name      string
+	Interface string
+	Cases     []OneofCase
+}
+
+type Struct struct {
+	Name       string
+	Attributes []Attribute
+	Oneofs     []Oneof
+}
+
+type Retry struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+}
+
+type Method struct {
+	Name      string
+	Input     string
+	HasOutput bool
+	Output    string
+	Retry     *Retry
+	Workers   int
+}
+
+type EnumMember struct {
+	Index string
+	Name  string
+}
+
+type Enum struct {
+	Name string
+	// Prefix is prepended to the constants of the members, so those of nested enums do not collide.
+	Prefix  string
+	Members []EnumMember
+}
+
+type Template struct {
+	Package  string
+	Structs  []Struct
+	Methods  []Method
+	Enums    []Enum
+	Imports  []string
+	Context  bool
+	Pointers bool
+	// PartitionKeys maps event types to the field, as a Go selector, that orders their handling.
+	PartitionKeys map[string]string
+	// IdempotencyKeys maps event types to the field, as a Go selector, that identifies their duplicates.
+	IdempotencyKeys map[string]string
+}
+
+// imports already present in codegen.tmpl
+var templateImports = []string{
+	"bufio",
+	"bytes",
+	"container/list",
+	"context",
+	"crypto/rand",
+	"encoding/hex",
+	"encoding/json",
+	"errors",
+	"fmt",
+	"hash/fnv",
+	"io",
+	"math/rand",
+	"os",
+	"path/filepath",
+	"runtime/debug",
+	"sort",
+	"sync",
+	"time",
+	"github.com/rs/zerolog",
+}
+
+// ExtraImports returns the imports that are not already part of codegen.tmpl.
+func (t Template) ExtraImports() []string {
+	var imports []string
+	for _, i := range t.Imports {
+		if !contains(templateImports, i) && !contains(imports, i) {
+			imports = append(imports, i)
+		}
+	}
+	return imports
+}
+
+// Events returns the unique event types consumed or produced by the methods, and the messages holding oneof cases
+// consumed by the methods.
+func (t Template) Events() []string {
+	var events []string
+	for _, method := range t.Methods {
+		if !contains(events, method.Input) {
+			events = append(events, method.Input)
+		}
+		if method.HasOutput && !contains(events, method.Output) {
+			events = append(events, method.Output)
+		}
+	}
+
+	for _, s := range t.Structs {
+		for _, oneof := range s.Oneofs {
+			for _, c := range oneof.Cases {
+				if contains(events, c.Name) && !contains(events, s.Name) {
+					events = append(events, s.Name)
+				}
+			}
+		}
+	}
+	return events
+}
+
+// TypedEvents returns the event types that get a Publish method of their own, leaving out the well known types mapped
+// to Go types such as *string, which have no name to give one.
+func (t Template) TypedEvents() []string {
+	wellKnown := make(map[string]struct{})
+	for _, o := range overWriteTypes {
+		wellKnown[o.Name] = struct{}{}
+	}
+
+	var events []string
+	for _, event := range t.Events() {
+		if _, ok := wellKnown[event]; !ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// RoutedCases returns the wrappers of the oneof cases that methods take, which are published on their own whenever a
+// message holding them is.
+func (t Template) RoutedCases(oneof Oneof) []string {
+	var cases []string
+	events := t.Events()
+	for _, c := range oneof.Cases {
+		if contains(events, c.Name) {
+			cases = append(cases, c.Name)
+		}
+	}
+	return cases
+}
+
+// Routed returns whether the struct holds oneof cases that methods take.
+func (t Template) Routed(s Struct) bool {
+	for _, oneof := range s.Oneofs {
+		if len(t.RoutedCases(oneof)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// UniqueMethods returns the methods with duplicates from multiple services removed.
+func (t Template) UniqueMethods() []Method {
+	var methods []Method
+	seen := make(map[string]struct{})
+	for _, method := range t.Methods {
+		if _, ok := seen[method.Name]; ok {
+			continue
+		}
+		seen[method.Name] = struct{}{}
+		methods = append(methods, method)
+	}
+	return methods
+}
+
+func contains(data []string, item string) bool {
+	for _, i := range data {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRetry(options []*parser.Option) (*Retry, error) {
+	var retry *Retry
+	for _, option := range options {
+		if !strings.HasPrefix(option.OptionName, "(eventbus.retry_") {
+			continue
+		}
+
+		if retry == nil {
+			retry = &Retry{}
+		}
+
+		value := option.Constant
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+
+		var err error
+		switch option.OptionName {
+		case "(eventbus.retry_max_attempts)":
+			retry.MaxAttempts, err = strconv.Atoi(value)
+		case "(eventbus.retry_initial_backoff)":
+			retry.InitialBackoff, err = time.ParseDuration(value)
+		case "(eventbus.retry_max_backoff)":
+			retry.MaxBackoff, err = time.ParseDuration(value)
+		case "(eventbus.retry_multiplier)":
+			retry.Multiplier, err = strconv.ParseFloat(value, 64)
+		case "(eventbus.retry_jitter)":
+			retry.Jitter, err = strconv.ParseFloat(value, 64)
+			if err == nil && (retry.Jitter < 0 || retry.Jitter > 1) {
+				err = fmt.Errorf("must be between 0 and 1")
+			}
+		default:
+			err = fmt.Errorf("unknown option")
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %s for option %s: %w", option.Constant, option.OptionName, err)
+		}
+	}
+	return retry, nil
+}
+
+func parseWorkers(options []*parser.Option) (int, error) {
+	for _, option := range options {
+		if option.OptionName != "(eventbus.workers)" {
+			continue
+		}
+
+		workers, err := strconv.Atoi(option.Constant)
+		if err == nil && workers < 1 {
+			err = fmt.Errorf("must be at least 1")
+		}
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %s for option %s: %w", option.Constant, option.OptionName, err)
+		}
+		return workers, nil
+	}
+	return 0, nil
+}
+
+func parseFieldFlag(options []*parser.FieldOption, name string) (bool, error) {
+	for _, option := range options {
+		if option.OptionName != name {
+			continue
+		}
+
+		flag, err := strconv.ParseBool(option.Constant)
+		if err != nil {
+			return false, fmt.Errorf("invalid value %s for option %s: %w", option.Constant, option.OptionName, err)
+		}
+		return flag, nil
+	}
+	return false, nil
+}
+
+// resolveField returns the name of the typ event and the Go selector of the field at path from it.  Fields of
+// messages declared in the proto may be referenced by their proto or Go name, other types are taken verbatim.
+func (t Template) resolveField(typ, path string) (string, string, error) {
+	structs := make(map[string]Struct)
+	for _, s := range t.Structs {
+		structs[s.Name] = s
+	}
+
+	name := typ
+	if s, ok := structs[strcase.ToCamel(typ)]; ok {
+		name = s.Name
+	}
+	if !contains(t.Events(), name) {
+		return "", "", fmt.Errorf("%s is not an event type of the bus", typ)
+	}
+
+	var selector []string
+	current := name
+	for _, field := range strings.Split(path, ".") {
+		s, ok := structs[current]
+		if !ok {
+			selector = append(selector, field)
+			current = ""
+			continue
+		}
+
+		var found bool
+		for _, attr := range s.Attributes {
+			if attr.Name == field || attr.RawName == field {
+				if attr.Repeated || strings.HasPrefix(attr.Type, "map[") {
+					return "", "", fmt.Errorf("must not be a repeated or map field")
+				}
+				selector = append(selector, attr.Name)
+				current = attr.Type
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", "", fmt.Errorf("message %s has no field %s", s.Name, field)
+		}
+	}
+	return name, strings.Join(selector, "."), nil
+}
+
+// SetPartitionKey marks the field at path, e.g. InstanceId or Resource.InstanceDetails.InstanceId, as the partition
+// key of the typ events.
+func (t *Template) SetPartitionKey(typ, path string) error {
+	name, selector, err := t.resolveField(typ, path)
+	if err != nil {
+		return fmt.Errorf("partition key %s of %s: %w", path, typ, err)
+	}
+
+	if t.PartitionKeys == nil {
+		t.PartitionKeys = make(map[string]string)
+	}
+	t.PartitionKeys[name] = selector
+	return nil
+}
+
+// SetIdempotencyKey marks the field at path as the idempotency key of the typ events.
+func (t *Template) SetIdempotencyKey(typ, path string) error {
+	name, selector, err := t.resolveField(typ, path)
+	if err != nil {
+		return fmt.Errorf("idempotency key %s of %s: %w", path, typ, err)
+	}
+
+	if t.IdempotencyKeys == nil {
+		t.IdempotencyKeys = make(map[string]string)
+	}
+	t.IdempotencyKeys[name] = selector
+	return nil
+}
+
+// protoType is a message, enum or oneof case declared in a file.
+type protoType struct {
+	Name string
+	Enum bool
+	// Import is the Go package of types that are not generated
+	Import string
+}
+
+// goType returns the Go type generated for the declaration.
+func (p protoType) goType() string {
+	if p.Enum {
+		return p.Name + "Enum"
+	}
+	return p.Name
+}
+
+// protoTypes holds the messages, enums and oneof cases declared in the parsed files, keyed by their full proto name,
+// e.g. types.Outer.Inner, and the proto packages of the files.
+type protoTypes struct {
+	declared map[string]protoType
+	packages map[string]struct{}
+	// strict fails on unresolved types, which may otherwise come from files that were not loaded
+	strict bool
+}
+
+func newProtoTypes(strict bool) protoTypes {
+	return protoTypes{
+		declared: make(map[string]protoType),
+		packages: make(map[string]struct{}),
+		strict:   strict,
+	}
+}
+
+// collect records the types declared in body, within the scope package or message.  The cases of oneofs are
+// recorded as types too, e.g. Outer.case, so rpcs can take them.  declare checks the Go type of each of them.
+func (p protoTypes) collect(body []parser.Visitee, scope, goScope string, nested bool, imp string, declare func(name, goName string) error) error {
+	for _, visitee := range body {
+		switch b := visitee.(type) {
+		case *parser.Message:
+			name := qualify(scope, b.MessageName)
+			p.declared[name] = protoType{Name: goScope + strcase.ToCamel(b.MessageName), Import: imp}
+			if err := declare(name, p.declared[name].goType()); err != nil {
+				return err
+			}
+			if err := p.collect(b.MessageBody, name, p.declared[name].Name, true, imp, declare); err != nil {
+				return err
+			}
+		case *parser.Enum:
+			name := qualify(scope, b.EnumName)
+			if nested {
+				p.declared[name] = protoType{Name: goScope + strcase.ToCamel(b.EnumName), Enum: true, Import: imp}
+			} else {
+				p.declared[name] = protoType{Name: goScope + b.EnumName, Enum: true, Import: imp}
+			}
+			if err := declare(name, p.declared[name].goType()); err != nil {
+				return err
+			}
+		case *parser.Oneof:
+			if err := declare(qualify(scope, b.OneofName), goScope+strcase.ToCamel(b.OneofName)); err != nil {
+				return err
+			}
+			for _, field := range b.OneofFields {
+				name := qualify(scope, field.FieldName)
+				p.declared[name] = protoType{Name: goScope + strcase.ToCamel(field.FieldName), Import: imp}
+				if err := declare(name, p.declared[name].goType()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns the type referenced as name from within the scope package or message, looking it up from the
+// innermost enclosing message outwards like protoc does.
+func (p protoTypes) resolve(scope, name string) (protoType, bool) {
+	if strings.HasPrefix(name, ".") {
+		declared, ok := p.declared[strings.TrimPrefix(name, ".")]
+		return declared, ok
+	}
+
+	for {
+		if declared, ok := p.declared[qualify(scope, name)]; ok {
+			return declared, true
+		}
+		if scope == "" {
+			return protoType{}, false
+		}
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// inPackage returns whether name is qualified by the package of a parsed file.
+func (p protoTypes) inPackage(name string) bool {
+	name = strings.TrimPrefix(name, ".")
+	for pkg := range p.packages {
+		if pkg != "" && strings.HasPrefix(name, pkg+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// resolveType returns the Go type of the proto type referenced from within the scope package or message, adding its
+// import when it comes from another Go package.  Qualified names outside of the parsed packages are taken as Go types,
+// i.e. events.CloudWatchEvent.
+func (t *Template) resolveType(types protoTypes, scope, protoType string) (string, error) {
+	if gType, ok := protoToGoTypes[protoType]; ok {
+		return gType, nil
+	}
+
+	if declared, ok := types.resolve(scope, protoType); ok {
+		t.addImport(declared.Import)
+		return declared.goType(), nil
+	}
+
+	if newType, ok := overWriteTypes[protoType]; ok {
+		t.addImport(newType.Import)
+		return newType.Name, nil
+	}
+
+	// the other well known types have no Go type, and are never generated from their files
+	if strings.HasPrefix(strings.TrimPrefix(protoType, "."), "google.protobuf.") {
+		return "", fmt.Errorf("unresolved type %s", strings.TrimPrefix(protoType, "."))
+	}
+
+	if !strings.Contains(protoType, ".") || types.inPackage(protoType) {
+		if types.strict {
+			return "", fmt.Errorf("unresolved type %s", protoType)
+		}
+		logger.Warn().Msgf("unresolved type %s", protoType)
+	}
+	return protoType, nil
+}
+
+// resolveMessage returns the Go type of the message an rpc takes or returns.  Unqualified names are also looked up
+// in camel case.
+func (t *Template) resolveMessage(types protoTypes, scope, name string) (string, error) {
+	if _, ok := types.resolve(scope, name); !ok && !strings.Contains(name, ".") {
+		name = strcase.ToCamel(name)
+	}
+	return t.resolveType(types, scope, name)
+}
+
+func (t *Template) addImport(imp string) {
+	if imp != "" && !contains(t.Imports, imp) {
+		t.Imports = append(t.Imports, imp)
+	}
+}
+
+// addMessage adds the struct of the message declared in the scope message, followed by the types nested in it.
+func (t *Template) addMessage(types protoTypes, m *parser.Message, scope string) error {
+	name := qualify(scope, m.MessageName)
+	msg := Struct{
+		Name: types.declared[name].Name,
+	}
+
+	var nested []*parser.Message
+	for _, attribute := range m.MessageBody {
+		switch f := attribute.(type) {
+		case *parser.Field:
+			gType, err := t.resolveType(types, name, f.Type)
+			if err != nil {
+				return fmt.Errorf("field %s of %s: %w", f.FieldName, name, err)
+			}
+
+			for _, key := range []struct {
+				option string
+				name   string
+				keys   *map[string]string
+			}{
+				{"(eventbus.partition_key)", "partition", &t.PartitionKeys},
+				{"(eventbus.idempotency_key)", "idempotency", &t.IdempotencyKeys},
+			} {
+				isKey, err := parseFieldFlag(f.FieldOptions, key.option)
+				if err != nil {
+					logger.Error().Err(err).Msgf("invalid option on field %s of %s", f.FieldName, msg.Name)
+					return err
+				}
+				if !isKey {
+					continue
+				}
+
+				if existing, ok := (*key.keys)[msg.Name]; ok {
+					return fmt.Errorf("message %s has multiple %s keys: %s | %s", msg.Name, key.name, existing, strcase.ToCamel(f.FieldName))
+				}
+				if f.IsRepeated {
+					return fmt.Errorf("%s key %s of %s must not be a repeated field", key.name, f.FieldName, msg.Name)
+				}
+				if *key.keys == nil {
+					*key.keys = make(map[string]string)
+				}
+				(*key.keys)[msg.Name] = strcase.ToCamel(f.FieldName)
+			}
+
+			msg.Attributes = append(msg.Attributes, Attribute{
+				Name:     strcase.ToCamel(f.FieldName),
+				Type:     gType,
+				RawName:  f.FieldName,
+				Optional: f.IsOptional,
+				Repeated: f.IsRepeated,
+			})
+		case *parser.MapField:
+			key, err := t.resolveType(types, name, f.KeyType)
+			if err != nil {
+				return fmt.Errorf("field %s of %s: %w", f.MapName, name, err)
+			}
+			value, err := t.resolveType(types, name, f.Type)
+			if err != nil {
+				return fmt.Errorf("field %s of %s: %w", f.MapName, name, err)
+			}
+
+			msg.Attributes = append(msg.Attributes, Attribute{
+				Name:    strcase.ToCamel(f.MapName),
+				Type:    fmt.Sprintf("map[%s]%s", key, value),
+				RawName: f.MapName,
+			})
+		case *parser.Oneof:
+			oneof := Oneof{
+				Name:      strcase.ToCamel(f.OneofName),
+				Interface: msg.Name + strcase.ToCamel(f.OneofName),
+			}
+			for _, field := range f.OneofFields {
+				gType, err := t.resolveType(types, name, field.Type)
+				if err != nil {
+					return fmt.Errorf("field %s of %s: %w", field.FieldName, name, err)
+				}
+
+				oneof.Cases = append(oneof.Cases, OneofCase{
+					Name:    types.declared[qualify(name, field.FieldName)].Name,
+					Field:   strcase.ToCamel(field.FieldName),
+					Type:    gType,
+					RawName: field.FieldName,
+				})
+			}
+			msg.Oneofs = append(msg.Oneofs, oneof)
+		case *parser.Message:
+			nested = append(nested, f)
+		case *parser.Enum:
+			goName := types.declared[qualify(name, f.EnumName)].Name
+			t.addEnum(f, goName, goName+"_")
+		default:
+			logger.Warn().Msgf("unsupported message attribute %s", reflect.TypeOf(f))
+		}
+	}
+	t.Structs = append(t.Structs, msg)
+
+	for _, m := range nested {
+		if err := t.addMessage(types, m, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Template) addEnum(e *parser.Enum, name string, prefix string) {
+	enum := Enum{
+		Name:   name,
+		Prefix: prefix,
+	}
+
+	for _, e := range e.EnumBody {
+		switch m := e.(type) {
+		case *parser.EnumField:
+			enum.Members = append(enum.Members, EnumMember{
+				Name:  m.Ident,
+				Index: m.Number,
+			})
+		default:
+			logger.Warn().Msgf("unsupported message attribute %s", reflect.TypeOf(m))
+		}
+	}
+
+	t.Enums = append(t.Enums, enum)
+}
+
+// New returns the Template of a single proto file, without loading the files it imports.
+func New(imports []string, proto io.Reader) (Template, error) {
+	parsedBuf, err := protoparser.Parse(proto)
+	if err != nil {
+		logger.Error().Err(err).Msg("error parsing protobuf")
+		return Template{Imports: imports}, err
+	}
+
+	return build(imports, []*protoFile{newProtoFile("", parsedBuf, true)}, false)
+}
+
+// build returns the Template of the parsed files, ordered so each file comes after the files it imports.  Messages
+// and enums are generated for every file without a Go package, services only for the input files.  Unresolved types
+// are errors when strict, i.e. once the imported files are loaded.
+func build(imports []string, files []*protoFile, strict bool) (Template, error) {
+	tmplData := Template{
+		Imports: imports,
+	}
+
+	pkg := ""
+	for _, file := range files {
+		if !file.input {
+			continue
+		}
+		if pkg != "" && file.pkg != pkg {
+			return tmplData, fmt.Errorf("input files have different packages: %s | %s", pkg, file.pkg)
+		}
+		pkg = file.pkg
+		tmplData.Package = file.goName
+	}
+
+	types := newProtoTypes(strict)
+	seen := make(map[string]string)
+	declare := func(name, goName string) error {
+		if other, ok := seen[goName]; ok {
+			return fmt.Errorf("%s generates type %s, already generated for %s", name, goName, other)
+		}
+		seen[goName] = name
+		return nil
+	}
+	for _, file := range files {
+		types.packages[file.pkg] = struct{}{}
+
+		var err error
+		if file.goPackage != "" {
+			err = types.collect(file.proto.ProtoBody, file.pkg, file.goName+".", false, file.goPackage, func(string, string) error { return nil })
+		} else {
+			err = types.collect(file.proto.ProtoBody, file.pkg, "", false, "", declare)
+		}
+		if err != nil {
+			logger.Error().Err(err).Msgf("error processing types in %s", file.name)
+			return tmplData, err
+		}
+	}
+
+	for _, file := range files {
+		if file.goPackage != "" {
+			continue
+		}
+		if err := tmplData.addFile(types, file); err != nil {
+			logger.Error().Err(err).Msgf("error processing %s", file.name)
+			return tmplData, err
+		}
+	}
+
+	for _, enum := range tmplData.Enums {
+		for _, member := range enum.Members {
+			name := enum.Name + "." + member.Name
+			constName := enum.Prefix + strings.ToUpper(member.Name)
+			if other, ok := seen[constName]; ok {
+				err := fmt.Errorf("%s generates constant %s, already generated for %s", name, constName, other)
+				logger.Error().Err(err).Msg("error processing enums")
+				return tmplData, err
+			}
+			seen[constName] = name
+		}
+	}
+
+	if len(tmplData.Methods) > 0 {
+		processedMethods := make(map[string]Method)
+		for _, method := range tmplData.Methods {
+			val, ok := processedMethods[method.Name]
+			if !ok {
+				processedMethods[method.Name] = method
+				continue
+			}
+
+			if val.Input != method.Input {
+				logger.Error().Msgf("Method %s has multiple inputs: %s | %s", method.Name, method.Input, val.Input)
+				return tmplData, fmt.Errorf("Method %s has multiple inputs: %s | %s", method.Name, method.Input, val.Input)
+			}
+
+			if val.HasOutput != method.HasOutput {
+				logger.Error().Msgf("Method %s has multiple return signatures", method.Name)
+				return tmplData, fmt.Errorf("Method %s has multiple return signatures", method.Name)
+			}
+
+			if val.Output != method.Output {
+				logger.Error().Msgf("Method %s has multiple outputs: %s | %s", method.Name, method.Output, val.Output)
+				return tmplData, fmt.Errorf("Method %s has multiple outputs: %s | %s", method.Name, method.Output, val.Output)
+			}
+		}
+	}
+
+	return tmplData, nil
+}
+
+// addFile adds the messages and enums of the file, and the rpcs of its services when it is an input file.
+func (t *Template) addFile(types protoTypes, file *protoFile) error {
+	var err error
+L:
+	for _, body := range file.proto.ProtoBody {
+		switch b := body.(type) {
+		case *parser.Service:
+			if !file.input {
+				continue
+			}
+
+			for _, visitee := range b.ServiceBody {
+				m, ok := visitee.(*parser.RPC)
+				if !ok {
+					logger.Warn().Msgf("unsupported service type %v", b)
+					continue L
+				}
+
+				method := Method{
+					Name: strcase.ToCamel(m.RPCName),
+				}
+
+				method.Retry, err = parseRetry(m.Options)
+				if err != nil {
+					logger.Error().Err(err).Msgf("error parsing options for rpc %s", m.RPCName)
+					return err
+				}
+
+				method.Workers, err = parseWorkers(m.Options)
+				if err != nil {
+					logger.Error().Err(err).Msgf("error parsing options for rpc %s", m.RPCName)
+					return err
+				}
+
+				method.Input, err = t.resolveMessage(types, file.pkg, m.RPCRequest.MessageType)
+				if err != nil {
+					return fmt.Errorf("rpc %s: %w", m.RPCName, err)
+				}
+
+				if m.RPCResponse.MessageType != "google.protobuf.Empty" {
+					method.HasOutput = true
+					method.Output, err = t.resolveMessage(types, file.pkg, m.RPCResponse.MessageType)
+					if err != nil {
+						return fmt.Errorf("rpc %s: %w", m.RPCName, err)
+					}
+				}
+
+				t.Methods = append(t.Methods, method)
+			}
+
+		case *parser.Message:
+			if err := t.addMessage(types, b, file.pkg); err != nil {
+				return err
+			}
+		case *parser.Enum:
+			t.addEnum(b, types.declared[qualify(file.pkg, b.EnumName)].Name, "")
+		case *parser.Package, *parser.Import:
+		default:
+			logger.Debug().Msgf("unsupported type %s", reflect.TypeOf(b))
+		}
+	}
+	return nil
+}
+
+type Config struct {
+	Imports  []string `yaml:"imports,omitempty"`
+	Context  bool     `yaml:"context,omitempty"`
+	Pointers bool     `yaml:"pointers,omitempty"`
+	// Workers sets the number of workers of methods by name, overriding their (eventbus.workers) option.
+	Workers map[string]int `yaml:"workers,omitempty"`
+	// PartitionKeys maps event types to the field whose value orders their handling, overriding their
+	// (eventbus.partition_key) option.
+	PartitionKeys map[string]string `yaml:"partition_keys,omitempty"`
+	// IdempotencyKeys maps event types to the field whose value identifies their duplicates, overriding their
+	// (eventbus.idempotency_key) option.
+	IdempotencyKeys map[string]string `yaml:"idempotency_keys,omitempty"`
+	// GoPackages maps imported proto files, by import location, to the Go package their types are referenced from
+	// instead of being generated.
+	GoPackages map[string]string `yaml:"go_packages,omitempty"`
+}
+
+func init() {
+	rootCmd = &cobra.Command{
+		Use:  "",
+		RunE: parse,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			logger = zerolog.New(cmd.OutOrStdout()).With().Timestamp().Logger()
+			zerolog.SetGlobalLevel(zerolog.InfoLevel)
+		},
+	}
+
+	rootCmd.PersistentFlags().StringSliceVar(&inFiles, "in", nil, "Protobuf input files, sharing the same package")
+	rootCmd.PersistentFlags().StringSliceVarP(&protoPaths, "proto_path", "I", nil, "Directories to search for imported protobuf files")
+	rootCmd.PersistentFlags().StringVar(&outFile, "out", "", "Generated Code output file")
+	rootCmd.PersistentFlags().StringVar(&confFile, "config", "", "Config file for code generation")
+	rootCmd.PersistentFlags().BoolVar(&withContext, "context", false, "Generate Service methods that accept a context.Context")
+	rootCmd.PersistentFlags().BoolVar(&withPointers, "pointers", false, "Generate Service methods that accept and return pointers to events")
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "protoc",
+		Short: "Run as a protoc plugin, reading a CodeGeneratorRequest on stdin",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunPlugin(cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+		},
+	})
+}
+
+// readConfig returns the Config in the file at path, or the zero Config when path is empty.
+func readConfig(path string) (Config, error) {
+	var config Config
+	if path == "" {
+		return config, nil
+	}
+
+	confStr, err := os.ReadFile(path)
+	if err != nil {
+		logger.Error().Err(err).Msgf("error reading config file %s", path)
+		return config, err
+	}
+
+	if err := yaml.Unmarshal(confStr, &config); err != nil {
+		logger.Error().Err(err).Msgf("error parsing config file %s", path)
+		return config, err
+	}
+	return config, nil
+}
+
+// configure applies the settings of the config file over the ones of the proto files.
+func (t *Template) configure(config Config) error {
+	t.Context = t.Context || config.Context
+	t.Pointers = t.Pointers || config.Pointers
+	for i, method := range t.Methods {
+		workers, ok := config.Workers[method.Name]
+		if !ok {
+			continue
+		}
+		if workers < 1 {
+			err := fmt.Errorf("invalid workers %d for method %s: must be at least 1", workers, method.Name)
+			logger.Error().Err(err).Msg("invalid workers in config file")
+			return err
+		}
+		t.Methods[i].Workers = workers
+	}
+	for typ, path := range config.PartitionKeys {
+		if err := t.SetPartitionKey(typ, path); err != nil {
+			logger.Error().Err(err).Msg("invalid partition key in config file")
+			return err
+		}
+	}
+	for typ, path := range config.IdempotencyKeys {
+		if err := t.SetIdempotencyKey(typ, path); err != nil {
+			logger.Error().Err(err).Msg("invalid idempotency key in config file")
+			return err
+		}
+	}
+	return nil
+}
+
+// render returns the formatted code generated for the Template.
+func render(tmplData Template) ([]byte, error) {
+	processedMethods := map[string]struct{}{}
+	funcMap := template.FuncMap{
+		"ToUpper": strings.ToUpper,
+		"ToCamel": strcase.ToCamel,
+		"ProcessedMethods": func(name string) bool {
+			_, ok := processedMethods[name]
+			if !ok {
+				processedMethods[name] = struct{}{}
+				return false
+			}
+			return true
+		},
+	}
+
+	tmpl, err := template.New("test").Funcs(funcMap).Parse(event_bus_tmpl)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed parsing generation template")
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	err = tmpl.Execute(buf, tmplData)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to render template")
+		return nil, err
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		logger.Error().Err(err).Msg("failed running gofmt on generated code")
+		return nil, err
+	}
+	return formatted, nil
+}
+
+func parse(cmd *cobra.Command, args []string) error {
+	if err := cmd.ParseFlags(args); err != nil {
+		return err
+	}
+
+	config, err := readConfig(confFile)
+	if err != nil {
+		return err
+	}
+
+	tmplData, err := Load(config.Imports, inFiles, protoPaths, config.GoPackages)
+	if err != nil {
+		logger.Error().Err(err).Msgf("error processing input files %s", strings.Join(inFiles, ", "))
+		return err
+	}
+	tmplData.Context = withContext
+	tmplData.Pointers = withPointers
+	if err := tmplData.configure(config); err != nil {
+		return err
+	}
+
+	formatted, err := render(tmplData)
+	if err != nil {
+		return err
+	}
+
+	fout, err := os.Create(outFile)
+	if err != nil {
+		logger.Error().Err(err).Msgf("error creating output file %s", outFile)
+		return err
+	}
+	defer fout.Close()
+
+	_, err = fout.Write(formatted)
+	if err != nil {
+		logger.Error().Err(err).Msgf("failed writing output file %s", outFile)
+		return err
+	}
+
+	return nil
+}
+
+// Execute runs the go-event-bus-gen command line.
+func Execute() error {
+	return rootCmd.Execute()
+}
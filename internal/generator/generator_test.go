@@ -0,0 +1,1545 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+type EventBusTestSuite struct {
+	suite.Suite
+}
+
+func (suite *EventBusTestSuite) SetupTest() {
+}
+
+func TestEventBusTestSuite(t *testing.T) {
+	suite.Run(t, new(EventBusTestSuite))
+}
+
+func (suite *EventBusTestSuite) TestAllTheTypes() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+import "google/protobuf/any.proto";
+import "google/protobuf/timestamp.proto";
+package types;
+
+message TypeRequest {
+    double                    p1 = 0;
+	float                     p2 = 1;
+	int32                     p3 = 2;
+	int64                     p4 = 3;
+	uint32                    p5 = 4;
+	uint64                    p6 = 5;
+	sint32                    p7 = 6;
+	sint64                    p8 = 7;
+	fixed32                   p9 = 8;
+	fixed64                   p10 = 9;
+	sfixed32                  p11 = 10;
+	sfixed64                  p12 = 11;
+	optional bool             p13 = 12;
+	repeated string           p14 = 13;
+	bytes                     p15 = 14;
+	google.protobuf.Any       p16 = 15;
+	google.protobuf.Timestamp p17 = 16;
+}
+
+service TypeService {
+  rpc HelloType (typeRequest) returns (google.protobuf.Empty) {}
+  rpc HelloTime (typeRequest) returns (google.protobuf.Timestamp) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), tmpl, Template{
+		Package: "types",
+		Structs: []Struct{
+			{
+				Name: "TypeRequest",
+				Attributes: []Attribute{
+					{
+						Name:    "P1",
+						Type:    "float64",
+						RawName: "p1",
+					},
+					{
+						Name:    "P2",
+						Type:    "float32",
+						RawName: "p2",
+					},
+					{
+						Name:    "P3",
+						Type:    "int32",
+						RawName: "p3",
+					},
+					{
+						Name:    "P4",
+						Type:    "int64",
+						RawName: "p4",
+					},
+					{
+						Name:    "P5",
+						Type:    "uint32",
+						RawName: "p5",
+					},
+					{
+						Name:    "P6",
+						Type:    "uint64",
+						RawName: "p6",
+					},
+					{
+						Name:    "P7",
+						Type:    "int32",
+						RawName: "p7",
+					},
+					{
+						Name:    "P8",
+						Type:    "int64",
+						RawName: "p8",
+					},
+					{
+						Name:    "P9",
+						Type:    "uint32",
+						RawName: "p9",
+					},
+					{
+						Name:    "P10",
+						Type:    "uint64",
+						RawName: "p10",
+					},
+					{
+						Name:    "P11",
+						Type:    "int32",
+						RawName: "p11",
+					},
+					{
+						Name:    "P12",
+						Type:    "int64",
+						RawName: "p12",
+					},
+					{
+						Name:     "P13",
+						Type:     "bool",
+						RawName:  "p13",
+						Optional: true,
+					},
+					{
+						Name:     "P14",
+						Type:     "string",
+						RawName:  "p14",
+						Repeated: true,
+					},
+					{
+						Name:    "P15",
+						Type:    "[]byte",
+						RawName: "p15",
+					},
+					{
+						Name:    "P16",
+						Type:    "any",
+						RawName: "p16",
+					},
+					{
+						Name:    "P17",
+						Type:    "time.Time",
+						RawName: "p17",
+					},
+				},
+			},
+		},
+		Methods: []Method{
+			{
+				Name:      "HelloType",
+				Input:     "TypeRequest",
+				HasOutput: false,
+			},
+			{
+				Name:      "HelloTime",
+				Input:     "TypeRequest",
+				HasOutput: true,
+				Output:    "time.Time",
+			},
+		},
+		Imports: []string{"time"},
+	})
+}
+
+func (suite *EventBusTestSuite) TestMaps() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message TypeRequest {
+    map<string, string> p1 = 0;
+	
+}
+
+service TypeService {
+  rpc HelloType (typeRequest) returns (google.protobuf.Empty) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), tmpl, Template{
+		Package: "types",
+		Structs: []Struct{
+			{
+				Name: "TypeRequest",
+				Attributes: []Attribute{
+					{
+						Name:    "P1",
+						Type:    "map[string]string",
+						RawName: "p1",
+					},
+				},
+			},
+		},
+		Methods: []Method{
+			{
+				Name:      "HelloType",
+				Input:     "TypeRequest",
+				HasOutput: false,
+			},
+		},
+		Imports: []string{},
+	})
+}
+
+func (suite *EventBusTestSuite) TestEnums() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+enum Status {
+  SUCCESS = 0;
+  FAILURE = 1;
+}
+
+message TypeRequest {
+	Status status = 0;
+}
+
+service TypeService {
+  rpc HelloType (typeRequest) returns (google.protobuf.Empty) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), tmpl, Template{
+		Package: "types",
+		Structs: []Struct{
+			{
+				Name: "TypeRequest",
+				Attributes: []Attribute{
+					{
+						Name:    "Status",
+						Type:    "StatusEnum",
+						RawName: "status",
+					},
+				},
+			},
+		},
+		Methods: []Method{
+			{
+				Name:      "HelloType",
+				Input:     "TypeRequest",
+				HasOutput: false,
+			},
+		},
+		Imports: []string{},
+		Enums: []Enum{
+			{
+				Name: "Status",
+				Members: []EnumMember{
+					{
+						Name:  "SUCCESS",
+						Index: "0",
+					},
+					{
+						Name:  "FAILURE",
+						Index: "1",
+					},
+				},
+			},
+		},
+	})
+}
+
+func (suite *EventBusTestSuite) TestConflictingMethodInputs() {
+	protof := `syntax = "proto3";
+package types;
+
+message TypeRequestA {
+	Status status = 0;
+}
+
+message TypeRequestB {
+	Status status = 0;
+}
+
+service TypeServiceA {
+  rpc HelloType (TypeRequestA) returns (google.protobuf.Empty) {}
+}
+
+service TypeServiceB {
+  rpc HelloType (TypeRequestB) returns (google.protobuf.Empty) {}
+}`
+
+	_, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Equal(suite.T(), err, fmt.Errorf("Method HelloType has multiple inputs: TypeRequestB | TypeRequestA"))
+}
+
+func (suite *EventBusTestSuite) TestConflictingMethodOutputs() {
+	protof := `syntax = "proto3";
+package types;
+
+message TypeRequest {
+	Status status = 0;
+}
+
+message TypeResponseA {
+	Status status = 0;
+}
+
+message TypeResponseB {
+	Status status = 0;
+}
+
+service TypeServiceA {
+  rpc HelloType (TypeRequest) returns (TypeResponseA) {}
+}
+
+service TypeServiceB {
+  rpc HelloType (TypeRequest) returns (TypeResponseB) {}
+}`
+
+	_, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Equal(suite.T(), err, fmt.Errorf("Method HelloType has multiple outputs: TypeResponseB | TypeResponseA"))
+}
+
+func (suite *EventBusTestSuite) TestConflictingMethodReturnSignatures() {
+	protof := `syntax = "proto3";
+package types;
+
+message TypeRequest {
+	Status status = 0;
+}
+
+message TypeResponse {
+	Status status = 0;
+}
+
+service TypeServiceA {
+  rpc HelloType (TypeRequest) returns (TypeResponse) {}
+}
+
+service TypeServiceB {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {}
+}`
+
+	_, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Equal(suite.T(), err, fmt.Errorf("Method HelloType has multiple return signatures"))
+}
+
+func (suite *EventBusTestSuite) TestRetryOptions() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message TypeRequest {
+	string name = 0;
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {
+    option (eventbus.retry_max_attempts) = 5;
+    option (eventbus.retry_initial_backoff) = "100ms";
+    option (eventbus.retry_max_backoff) = "2s";
+    option (eventbus.retry_multiplier) = 1.5;
+    option (eventbus.retry_jitter) = 0.2;
+  }
+  rpc HelloAgain (TypeRequest) returns (google.protobuf.Empty) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), tmpl.Methods, []Method{
+		{
+			Name:  "HelloType",
+			Input: "TypeRequest",
+			Retry: &Retry{
+				MaxAttempts:    5,
+				InitialBackoff: 100 * time.Millisecond,
+				MaxBackoff:     2 * time.Second,
+				Multiplier:     1.5,
+				Jitter:         0.2,
+			},
+		},
+		{
+			Name:  "HelloAgain",
+			Input: "TypeRequest",
+		},
+	})
+}
+
+func (suite *EventBusTestSuite) TestInvalidRetryOptions() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message TypeRequest {
+	string name = 0;
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {
+    option (eventbus.retry_initial_backoff) = "soon";
+  }
+}`
+
+	_, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.EqualError(suite.T(), err, `invalid value "soon" for option (eventbus.retry_initial_backoff): time: invalid duration "soon"`)
+}
+
+func (suite *EventBusTestSuite) TestInvalidRetryJitter() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message TypeRequest {
+	string name = 0;
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {
+    option (eventbus.retry_jitter) = 1.5;
+  }
+}`
+
+	_, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.EqualError(suite.T(), err, `invalid value 1.5 for option (eventbus.retry_jitter): must be between 0 and 1`)
+}
+
+func (suite *EventBusTestSuite) TestWorkersOption() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message TypeRequest {
+	string name = 0;
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {
+    option (eventbus.workers) = 4;
+  }
+  rpc HelloAgain (TypeRequest) returns (google.protobuf.Empty) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), tmpl.Methods, []Method{
+		{
+			Name:    "HelloType",
+			Input:   "TypeRequest",
+			Workers: 4,
+		},
+		{
+			Name:  "HelloAgain",
+			Input: "TypeRequest",
+		},
+	})
+}
+
+func (suite *EventBusTestSuite) TestInvalidWorkersOption() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message TypeRequest {
+	string name = 0;
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {
+    option (eventbus.workers) = 0;
+  }
+}`
+
+	_, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.EqualError(suite.T(), err, `invalid value 0 for option (eventbus.workers): must be at least 1`)
+}
+
+func (suite *EventBusTestSuite) TestInvalidWorkersConfig() {
+	tmpl := Template{
+		Methods: []Method{
+			{
+				Name:  "HelloType",
+				Input: "TypeRequest",
+			},
+		},
+	}
+
+	assert.EqualError(suite.T(), tmpl.configure(Config{Workers: map[string]int{"HelloType": 0}}), "invalid workers 0 for method HelloType: must be at least 1")
+	assert.EqualError(suite.T(), tmpl.configure(Config{Workers: map[string]int{"HelloType": -1}}), "invalid workers -1 for method HelloType: must be at least 1")
+	assert.Nil(suite.T(), tmpl.configure(Config{Workers: map[string]int{"HelloType": 2}}))
+	assert.Equal(suite.T(), 2, tmpl.Methods[0].Workers)
+}
+
+func (suite *EventBusTestSuite) TestWellKnownTypes() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+import "google/protobuf/duration.proto";
+import "google/protobuf/struct.proto";
+import "google/protobuf/wrappers.proto";
+package types;
+
+message TypeRequest {
+	google.protobuf.Duration timeout = 0;
+	google.protobuf.Struct metadata = 1;
+	google.protobuf.Value value = 2;
+	google.protobuf.ListValue values = 3;
+	google.protobuf.StringValue name = 4;
+	google.protobuf.Int64Value count = 5;
+	google.protobuf.BytesValue payload = 6;
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"time"}, tmpl.Imports)
+	var types []string
+	for _, attribute := range tmpl.Structs[0].Attributes {
+		types = append(types, attribute.Type)
+	}
+	assert.Equal(suite.T(), []string{"time.Duration", "map[string]any", "any", "[]any", "*string", "*int64", "[]byte"}, types)
+}
+
+func (suite *EventBusTestSuite) TestUnsupportedWellKnownType() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+import "google/protobuf/type.proto";
+package types;
+
+message TypeRequest {
+	google.protobuf.Type type = 0;
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {}
+}`
+
+	_, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.EqualError(suite.T(), err, "field type of types.TypeRequest: unresolved type google.protobuf.Type")
+}
+
+func (suite *EventBusTestSuite) TestExtraImports() {
+	tmpl := Template{
+		Imports: []string{"time", "github.com/aws/aws-lambda-go/events", "time"},
+	}
+	assert.Equal(suite.T(), []string{"github.com/aws/aws-lambda-go/events"}, tmpl.ExtraImports())
+}
+
+func (suite *EventBusTestSuite) TestPartitionKeyOption() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message InstanceDetails {
+	string region = 0;
+	string instance_id = 1 [(eventbus.partition_key) = true];
+}
+
+message Finding {
+	string id = 0;
+	InstanceDetails instance_details = 1;
+}
+
+service TypeService {
+  rpc StopInstance (InstanceDetails) returns (google.protobuf.Empty) {}
+  rpc Evaluate (Finding) returns (google.protobuf.Empty) {}
+  rpc ParseEvent (events.CloudWatchEvent) returns (Finding) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), map[string]string{"InstanceDetails": "InstanceId"}, tmpl.PartitionKeys)
+
+	assert.Nil(suite.T(), tmpl.SetPartitionKey("Finding", "instance_details.InstanceId"))
+	assert.Nil(suite.T(), tmpl.SetPartitionKey("events.CloudWatchEvent", "ID"))
+	assert.Equal(suite.T(), map[string]string{
+		"InstanceDetails":        "InstanceId",
+		"Finding":                "InstanceDetails.InstanceId",
+		"events.CloudWatchEvent": "ID",
+	}, tmpl.PartitionKeys)
+
+	assert.EqualError(suite.T(), tmpl.SetPartitionKey("Finding", "Severity"), `partition key Severity of Finding: message Finding has no field Severity`)
+	assert.EqualError(suite.T(), tmpl.SetPartitionKey("Reqq", "Name"), `partition key Name of Reqq: Reqq is not an event type of the bus`)
+}
+
+func (suite *EventBusTestSuite) TestMultiplePartitionKeys() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message TypeRequest {
+	string name = 0 [(eventbus.partition_key) = true];
+	string id = 1 [(eventbus.partition_key) = true];
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {}
+}`
+
+	_, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.EqualError(suite.T(), err, `message TypeRequest has multiple partition keys: Name | Id`)
+}
+
+func (suite *EventBusTestSuite) TestIdempotencyKeyOption() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message AccessKeyDetails {
+	string access_key_id = 0 [(eventbus.partition_key) = true, (eventbus.idempotency_key) = true];
+	repeated string tags = 1;
+}
+
+service TypeService {
+  rpc DisableAccessKey (AccessKeyDetails) returns (google.protobuf.Empty) {}
+  rpc ParseEvent (events.CloudWatchEvent) returns (AccessKeyDetails) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), map[string]string{"AccessKeyDetails": "AccessKeyId"}, tmpl.PartitionKeys)
+	assert.Equal(suite.T(), map[string]string{"AccessKeyDetails": "AccessKeyId"}, tmpl.IdempotencyKeys)
+
+	assert.Nil(suite.T(), tmpl.SetIdempotencyKey("events.CloudWatchEvent", "ID"))
+	assert.Equal(suite.T(), "ID", tmpl.IdempotencyKeys["events.CloudWatchEvent"])
+	assert.EqualError(suite.T(), tmpl.SetIdempotencyKey("AccessKeyDetails", "tags"), `idempotency key tags of AccessKeyDetails: must not be a repeated or map field`)
+	assert.EqualError(suite.T(), tmpl.SetIdempotencyKey("AccessKeyDetail", "AccessKeyId"), `idempotency key AccessKeyId of AccessKeyDetail: AccessKeyDetail is not an event type of the bus`)
+}
+
+func (suite *EventBusTestSuite) TestNestedTypes() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message Outer {
+	enum Status {
+		SUCCESS = 0;
+		FAILURE = 1;
+	}
+
+	message Inner {
+		Status status = 0;
+	}
+
+	Inner inner = 0;
+	Status status = 1;
+}
+
+message TypeReply {
+	Outer.Inner inner = 0;
+	.types.Outer.Status status = 1;
+}
+
+service TypeService {
+  rpc HelloType (Outer.Inner) returns (types.TypeReply) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), tmpl, Template{
+		Package: "types",
+		Structs: []Struct{
+			{
+				Name: "Outer",
+				Attributes: []Attribute{
+					{
+						Name:    "Inner",
+						Type:    "OuterInner",
+						RawName: "inner",
+					},
+					{
+						Name:    "Status",
+						Type:    "OuterStatusEnum",
+						RawName: "status",
+					},
+				},
+			},
+			{
+				Name: "OuterInner",
+				Attributes: []Attribute{
+					{
+						Name:    "Status",
+						Type:    "OuterStatusEnum",
+						RawName: "status",
+					},
+				},
+			},
+			{
+				Name: "TypeReply",
+				Attributes: []Attribute{
+					{
+						Name:    "Inner",
+						Type:    "OuterInner",
+						RawName: "inner",
+					},
+					{
+						Name:    "Status",
+						Type:    "OuterStatusEnum",
+						RawName: "status",
+					},
+				},
+			},
+		},
+		Methods: []Method{
+			{
+				Name:      "HelloType",
+				Input:     "OuterInner",
+				HasOutput: true,
+				Output:    "TypeReply",
+			},
+		},
+		Imports: []string{},
+		Enums: []Enum{
+			{
+				Name:   "OuterStatus",
+				Prefix: "OuterStatus_",
+				Members: []EnumMember{
+					{
+						Name:  "SUCCESS",
+						Index: "0",
+					},
+					{
+						Name:  "FAILURE",
+						Index: "1",
+					},
+				},
+			},
+		},
+	})
+}
+
+func (suite *EventBusTestSuite) TestOneof() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message TypeRequest {
+	string id = 0;
+	oneof choice {
+		string name = 1;
+		TypeReply reply = 2;
+	}
+}
+
+message TypeReply {
+	string message = 0;
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest.name) returns (google.protobuf.Empty) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []Struct{
+		{
+			Name: "TypeRequest",
+			Attributes: []Attribute{
+				{
+					Name:    "Id",
+					Type:    "string",
+					RawName: "id",
+				},
+			},
+			Oneofs: []Oneof{
+				{
+					Name:      "Choice",
+					Interface: "TypeRequestChoice",
+					Cases: []OneofCase{
+						{
+							Name:    "TypeRequestName",
+							Field:   "Name",
+							Type:    "string",
+							RawName: "name",
+						},
+						{
+							Name:    "TypeRequestReply",
+							Field:   "Reply",
+							Type:    "TypeReply",
+							RawName: "reply",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "TypeReply",
+			Attributes: []Attribute{
+				{
+					Name:    "Message",
+					Type:    "string",
+					RawName: "message",
+				},
+			},
+		},
+	}, tmpl.Structs)
+	assert.Equal(suite.T(), []Method{
+		{
+			Name:  "HelloType",
+			Input: "TypeRequestName",
+		},
+	}, tmpl.Methods)
+	assert.Equal(suite.T(), []string{"TypeRequestName"}, tmpl.RoutedCases(tmpl.Structs[0].Oneofs[0]))
+	// the message holding the case can be published without a method taking it
+	assert.Equal(suite.T(), []string{"TypeRequestName", "TypeRequest"}, tmpl.Events())
+}
+
+func (suite *EventBusTestSuite) TestConflictingOneofCase() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message TypeRequest {
+	message Name {
+		string value = 0;
+	}
+
+	oneof choice {
+		string name = 1;
+	}
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {}
+}`
+
+	_, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.EqualError(suite.T(), err, `types.TypeRequest.name generates type TypeRequestName, already generated for types.TypeRequest.Name`)
+}
+
+func (suite *EventBusTestSuite) TestMapTypes() {
+	keys := map[string]string{
+		"string":   "string",
+		"int32":    "int32",
+		"int64":    "int64",
+		"uint32":   "uint32",
+		"uint64":   "uint64",
+		"sint32":   "int32",
+		"sint64":   "int64",
+		"fixed32":  "uint32",
+		"fixed64":  "uint64",
+		"sfixed32": "int32",
+		"sfixed64": "int64",
+		"bool":     "bool",
+	}
+
+	values := []struct {
+		proto   string
+		goType  string
+		imports []string
+	}{
+		{"string", "string", []string{}},
+		{"double", "float64", []string{}},
+		{"bytes", "[]byte", []string{}},
+		{"TypeReply", "TypeReply", []string{}},
+		{"Outer.Inner", "OuterInner", []string{}},
+		{"Status", "StatusEnum", []string{}},
+		{"Outer.Kind", "OuterKindEnum", []string{}},
+		{"google.protobuf.Timestamp", "time.Time", []string{"time"}},
+		{"google.protobuf.Any", "any", []string{}},
+		{"events.CloudWatchEvent", "events.CloudWatchEvent", []string{}},
+	}
+
+	for key, goKey := range keys {
+		for _, value := range values {
+			protof := fmt.Sprintf(`syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+enum Status {
+  SUCCESS = 0;
+}
+
+message Outer {
+	enum Kind {
+		CHEDDAR = 0;
+	}
+
+	message Inner {
+		string name = 0;
+	}
+}
+
+message TypeReply {
+	string message = 0;
+}
+
+message TypeRequest {
+	map<%s, %s> values = 0;
+}
+
+service TypeService {
+  rpc HelloType (TypeRequest) returns (google.protobuf.Empty) {}
+}`, key, value.proto)
+
+			tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+			assert.Nil(suite.T(), err)
+
+			expected := fmt.Sprintf("map[%s]%s", goKey, value.goType)
+			assert.Equal(suite.T(), []Attribute{
+				{
+					Name:    "Values",
+					Type:    expected,
+					RawName: "values",
+				},
+			}, tmpl.Structs[len(tmpl.Structs)-1].Attributes, "map<%s, %s>", key, value.proto)
+			assert.Equal(suite.T(), value.imports, tmpl.Imports, "map<%s, %s>", key, value.proto)
+		}
+	}
+}
+
+func (suite *EventBusTestSuite) TestNestedMapValue() {
+	protof := `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package types;
+
+message Outer {
+	message Inner {
+		string name = 0;
+	}
+
+	map<string, Inner> inners = 0;
+}
+
+service TypeService {
+  rpc HelloType (Outer) returns (google.protobuf.Empty) {}
+}`
+
+	tmpl, err := New([]string{}, bytes.NewReader([]byte(protof)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "map[string]OuterInner", tmpl.Structs[0].Attributes[0].Type)
+}
+
+// writeProtos writes the files, keyed by path relative to a temporary directory, and returns that directory.
+func (suite *EventBusTestSuite) writeProtos(files map[string]string) string {
+	dir := suite.T().TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.Nil(suite.T(), os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.Nil(suite.T(), os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+var commonProto = `syntax = "proto3";
+package common;
+
+enum Currency {
+	USD = 0;
+	EUR = 1;
+}
+
+message Money {
+	Currency currency = 0;
+	int64 cents = 1;
+}`
+
+func (suite *EventBusTestSuite) TestLoadImports() {
+	dir := suite.writeProtos(map[string]string{
+		"protos/common/money.proto": commonProto,
+		"orders.proto": `syntax = "proto3";
+import "google/protobuf/empty.proto";
+import "common/money.proto";
+package orders;
+
+message Order {
+	common.Money total = 0;
+	.common.Currency currency = 1;
+}
+
+service OrderService {
+  rpc PlaceOrder (Order) returns (google.protobuf.Empty) {}
+}`,
+	})
+
+	tmpl, err := Load([]string{}, []string{filepath.Join(dir, "orders.proto")}, []string{filepath.Join(dir, "protos")}, nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "orders", tmpl.Package)
+	assert.Equal(suite.T(), []Struct{
+		{
+			Name: "Money",
+			Attributes: []Attribute{
+				{Name: "Currency", Type: "CurrencyEnum", RawName: "currency"},
+				{Name: "Cents", Type: "int64", RawName: "cents"},
+			},
+		},
+		{
+			Name: "Order",
+			Attributes: []Attribute{
+				{Name: "Total", Type: "Money", RawName: "total"},
+				{Name: "Currency", Type: "CurrencyEnum", RawName: "currency"},
+			},
+		},
+	}, tmpl.Structs)
+	assert.Equal(suite.T(), []Enum{
+		{
+			Name: "Currency",
+			Members: []EnumMember{
+				{Index: "0", Name: "USD"},
+				{Index: "1", Name: "EUR"},
+			},
+		},
+	}, tmpl.Enums)
+	assert.Len(suite.T(), tmpl.Methods, 1)
+}
+
+func (suite *EventBusTestSuite) TestLoadGoPackage() {
+	dir := suite.writeProtos(map[string]string{
+		"common/money.proto": commonProto,
+		"orders.proto": `syntax = "proto3";
+import "google/protobuf/empty.proto";
+import "common/money.proto";
+package orders;
+
+message Order {
+	common.Money total = 0;
+}
+
+service OrderService {
+  rpc PlaceOrder (Order) returns (google.protobuf.Empty) {}
+}`,
+	})
+
+	tmpl, err := Load([]string{}, []string{filepath.Join(dir, "orders.proto")}, nil, map[string]string{
+		"common/money.proto": "github.com/acme/common",
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"github.com/acme/common"}, tmpl.Imports)
+	assert.Equal(suite.T(), []Struct{
+		{
+			Name: "Order",
+			Attributes: []Attribute{
+				{Name: "Total", Type: "common.Money", RawName: "total"},
+			},
+		},
+	}, tmpl.Structs)
+	assert.Empty(suite.T(), tmpl.Enums)
+}
+
+func (suite *EventBusTestSuite) TestLoadMultipleFiles() {
+	dir := suite.writeProtos(map[string]string{
+		"orders.proto": `syntax = "proto3";
+package shop;
+
+message Order {
+	string id = 0;
+}
+
+service OrderService {
+  rpc PlaceOrder (Order) returns (Charge) {}
+}`,
+		"payments.proto": `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package shop;
+
+message Charge {
+	string order_id = 0;
+}
+
+service PaymentService {
+  rpc ChargeCard (Charge) returns (google.protobuf.Empty) {}
+}`,
+		"other.proto": `syntax = "proto3";
+package other;`,
+	})
+
+	tmpl, err := Load([]string{}, []string{filepath.Join(dir, "orders.proto"), filepath.Join(dir, "payments.proto")}, nil, nil)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), tmpl.Structs, 2)
+	assert.Equal(suite.T(), []string{"PlaceOrder", "ChargeCard"}, []string{tmpl.Methods[0].Name, tmpl.Methods[1].Name})
+
+	_, err = Load([]string{}, []string{filepath.Join(dir, "orders.proto"), filepath.Join(dir, "other.proto")}, nil, nil)
+	assert.EqualError(suite.T(), err, "input files have different packages: shop | other")
+}
+
+func (suite *EventBusTestSuite) TestEnumValueCollision() {
+	dir := suite.writeProtos(map[string]string{
+		"protos/common/money.proto": commonProto,
+		"orders.proto": `syntax = "proto3";
+import "google/protobuf/empty.proto";
+import "common/money.proto";
+package orders;
+
+enum Region {
+	USD = 0;
+}
+
+message Order {
+	common.Money total = 0;
+	Region region = 1;
+}
+
+service OrderService {
+  rpc PlaceOrder (Order) returns (google.protobuf.Empty) {}
+}`,
+	})
+
+	_, err := Load([]string{}, []string{filepath.Join(dir, "orders.proto")}, []string{filepath.Join(dir, "protos")}, nil)
+	assert.EqualError(suite.T(), err, "Region.USD generates constant USD, already generated for Currency.USD")
+}
+
+func (suite *EventBusTestSuite) TestLoadErrors() {
+	dir := suite.writeProtos(map[string]string{
+		"a.proto": `syntax = "proto3";
+import "b.proto";
+package cycle;`,
+		"b.proto": `syntax = "proto3";
+import "a.proto";
+package cycle;`,
+		"missing.proto": `syntax = "proto3";
+import "nowhere.proto";
+package missing;`,
+		"unresolved.proto": `syntax = "proto3";
+import "google/protobuf/empty.proto";
+package unresolved;
+
+message Order {
+	Money total = 0;
+}
+
+service OrderService {
+  rpc PlaceOrder (Order) returns (google.protobuf.Empty) {}
+}`,
+	})
+
+	_, err := Load([]string{}, []string{filepath.Join(dir, "a.proto")}, nil, nil)
+	assert.EqualError(suite.T(), err, fmt.Sprintf("import cycle: %[1]s/a.proto -> %[1]s/b.proto -> %[1]s/a.proto", dir))
+
+	_, err = Load([]string{}, []string{filepath.Join(dir, "missing.proto")}, nil, nil)
+	assert.EqualError(suite.T(), err, fmt.Sprintf("%[1]s/missing.proto: import nowhere.proto not found in %[1]s", dir))
+
+	_, err = Load([]string{}, []string{filepath.Join(dir, "unresolved.proto")}, nil, nil)
+	assert.EqualError(suite.T(), err, "field total of unresolved.Order: unresolved type Money")
+}
+
+// methodOptions returns method options with the (eventbus.workers) and (eventbus.retry_*) extensions set, encoded
+// as protoc does.
+func methodOptions() *descriptorpb.MethodOptions {
+	var b []byte
+	b = protowire.AppendTag(b, 51001, protowire.VarintType)
+	b = protowire.AppendVarint(b, 4)
+	b = protowire.AppendTag(b, 51003, protowire.BytesType)
+	b = protowire.AppendString(b, "200ms")
+	b = protowire.AppendTag(b, 51006, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(0.2))
+
+	options := &descriptorpb.MethodOptions{}
+	options.ProtoReflect().SetUnknown(b)
+	return options
+}
+
+func fieldOptions() *descriptorpb.FieldOptions {
+	var b []byte
+	b = protowire.AppendTag(b, 51001, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+
+	options := &descriptorpb.FieldOptions{}
+	options.ProtoReflect().SetUnknown(b)
+	return options
+}
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+// pluginRequest returns the request protoc sends for orders.proto, importing common/money.proto.
+func pluginRequest() *pluginpb.CodeGeneratorRequest {
+	extension := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, extendee string) *descriptorpb.FieldDescriptorProto {
+		f := field(name, number, typ, "")
+		f.Extendee = proto.String(extendee)
+		return f
+	}
+	options := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("eventbus/options.proto"),
+		Package:    proto.String("eventbus"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Extension: []*descriptorpb.FieldDescriptorProto{
+			extension("workers", 51001, descriptorpb.FieldDescriptorProto_TYPE_INT32, ".google.protobuf.MethodOptions"),
+			extension("retry_max_attempts", 51002, descriptorpb.FieldDescriptorProto_TYPE_INT32, ".google.protobuf.MethodOptions"),
+			extension("retry_initial_backoff", 51003, descriptorpb.FieldDescriptorProto_TYPE_STRING, ".google.protobuf.MethodOptions"),
+			extension("retry_max_backoff", 51004, descriptorpb.FieldDescriptorProto_TYPE_STRING, ".google.protobuf.MethodOptions"),
+			extension("retry_multiplier", 51005, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, ".google.protobuf.MethodOptions"),
+			extension("retry_jitter", 51006, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, ".google.protobuf.MethodOptions"),
+			extension("partition_key", 51001, descriptorpb.FieldDescriptorProto_TYPE_BOOL, ".google.protobuf.FieldOptions"),
+			extension("idempotency_key", 51002, descriptorpb.FieldDescriptorProto_TYPE_BOOL, ".google.protobuf.FieldOptions"),
+		},
+		Syntax: proto.String("proto3"),
+	}
+
+	money := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("common/money.proto"),
+		Package: proto.String("common"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: proto.String("Currency"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("USD"), Number: proto.Int32(0)},
+					{Name: proto.String("EUR"), Number: proto.Int32(1)},
+				},
+			},
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Money"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("currency", 1, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".common.Currency"),
+					field("cents", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+				},
+			},
+		},
+		Syntax: proto.String("proto3"),
+	}
+
+	id := field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	id.Options = fieldOptions()
+	counts := field("counts", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".orders.Order.CountsEntry")
+	counts.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	card := field("credit_card", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".orders.Order.Card")
+	card.OneofIndex = proto.Int32(0)
+	voucher := field("voucher", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	voucher.OneofIndex = proto.Int32(0)
+	note := field("note", 7, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	note.OneofIndex = proto.Int32(1)
+	note.Proto3Optional = proto.Bool(true)
+	tags := field("tags", 8, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	tags.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	orders := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("orders.proto"),
+		Package:    proto.String("orders"),
+		Dependency: []string{"google/protobuf/empty.proto", "google/protobuf/timestamp.proto", "common/money.proto", "eventbus/options.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					id,
+					field("total", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".common.Money"),
+					field("created", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+					counts,
+					card,
+					voucher,
+					note,
+					tags,
+					field("status", 9, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".orders.Order.Status"),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("CountsEntry"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+							field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+						},
+						Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+					},
+					{
+						Name: proto.String("Card"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							field("number", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+						},
+					},
+				},
+				EnumType: []*descriptorpb.EnumDescriptorProto{
+					{
+						Name: proto.String("Status"),
+						Value: []*descriptorpb.EnumValueDescriptorProto{
+							{Name: proto.String("PENDING"), Number: proto.Int32(0)},
+						},
+					},
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{
+					{Name: proto.String("payment")},
+					{Name: proto.String("_note")},
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("OrderService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("PlaceOrder"),
+						InputType:  proto.String(".orders.Order"),
+						OutputType: proto.String(".google.protobuf.Empty"),
+						Options:    methodOptions(),
+					},
+					{
+						Name:       proto.String("Pay"),
+						InputType:  proto.String(".orders.Order.credit_card"),
+						OutputType: proto.String(".common.Money"),
+					},
+				},
+			},
+		},
+		Syntax: proto.String("proto3"),
+	}
+
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"orders.proto"},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			{Name: proto.String("google/protobuf/descriptor.proto"), Package: proto.String("google.protobuf")},
+			{Name: proto.String("google/protobuf/empty.proto"), Package: proto.String("google.protobuf")},
+			{Name: proto.String("google/protobuf/timestamp.proto"), Package: proto.String("google.protobuf")},
+			options,
+			money,
+			orders,
+		},
+	}
+}
+
+func (suite *EventBusTestSuite) TestFromRequest() {
+	dir := suite.writeProtos(map[string]string{
+		"common/money.proto": commonProto,
+		"orders.proto": `syntax = "proto3";
+import "google/protobuf/empty.proto";
+import "google/protobuf/timestamp.proto";
+import "common/money.proto";
+package orders;
+
+message Order {
+	string id = 1 [(eventbus.partition_key) = true];
+	common.Money total = 2;
+	google.protobuf.Timestamp created = 3;
+	map<string, int32> counts = 4;
+	oneof payment {
+		Card credit_card = 5;
+		string voucher = 6;
+	}
+	optional string note = 7;
+	repeated string tags = 8;
+	Status status = 9;
+
+	enum Status {
+		PENDING = 0;
+	}
+
+	message Card {
+		string number = 1;
+	}
+}
+
+service OrderService {
+  rpc PlaceOrder (Order) returns (google.protobuf.Empty) {
+    option (eventbus.workers) = 4;
+    option (eventbus.retry_initial_backoff) = "200ms";
+    option (eventbus.retry_jitter) = 0.2;
+  }
+  rpc Pay (Order.credit_card) returns (common.Money) {}
+}`,
+	})
+
+	loaded, err := Load([]string{}, []string{filepath.Join(dir, "orders.proto")}, []string{dir}, nil)
+	assert.Nil(suite.T(), err)
+
+	tmpl, err := FromRequest(pluginRequest(), []string{}, nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), loaded, tmpl)
+	assert.Equal(suite.T(), map[string]string{"Order": "Id"}, tmpl.PartitionKeys)
+	assert.Equal(suite.T(), 4, tmpl.Methods[0].Workers)
+	assert.Equal(suite.T(), &Retry{InitialBackoff: 200 * time.Millisecond, Jitter: 0.2}, tmpl.Methods[0].Retry)
+}
+
+func (suite *EventBusTestSuite) TestFromRequestGoPackage() {
+	req := pluginRequest()
+	req.ProtoFile[4].Options = &descriptorpb.FileOptions{GoPackage: proto.String("github.com/acme/common;common")}
+	req.ProtoFile[5].Options = &descriptorpb.FileOptions{GoPackage: proto.String("github.com/acme/orders")}
+
+	tmpl, err := FromRequest(req, []string{}, nil)
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), tmpl.Imports, "github.com/acme/common")
+	assert.Equal(suite.T(), "common.Money", tmpl.Structs[0].Attributes[1].Type)
+	assert.Equal(suite.T(), "common.Money", tmpl.Methods[1].Output)
+
+	req.ProtoFile[4].Options = nil
+	tmpl, err = FromRequest(req, []string{}, map[string]string{"common/money.proto": "github.com/acme/money"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"github.com/acme/money", "time"}, tmpl.Imports)
+	assert.Equal(suite.T(), "money.Money", tmpl.Structs[0].Attributes[1].Type)
+}
+
+func (suite *EventBusTestSuite) TestFromRequestWellKnownTypes() {
+	req := pluginRequest()
+	order := req.ProtoFile[5].MessageType[0]
+	order.Field = []*descriptorpb.FieldDescriptorProto{
+		field("ttl", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Duration"),
+		field("note", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.StringValue"),
+		field("metadata", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Struct"),
+		field("mask", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.FieldMask"),
+	}
+	order.NestedType = nil
+	order.EnumType = nil
+	order.OneofDecl = nil
+	req.ProtoFile[5].Service[0].Method = req.ProtoFile[5].Service[0].Method[:1]
+
+	tmpl, err := FromRequest(req, []string{}, nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"time"}, tmpl.Imports)
+	assert.Equal(suite.T(), []Attribute{
+		{Name: "Ttl", Type: "time.Duration", RawName: "ttl"},
+		{Name: "Note", Type: "*string", RawName: "note"},
+		{Name: "Metadata", Type: "map[string]any", RawName: "metadata"},
+		{Name: "Mask", Type: "[]string", RawName: "mask"},
+	}, tmpl.Structs[1].Attributes)
+
+	_, err = render(tmpl)
+	assert.Nil(suite.T(), err)
+
+	order.Field = append(order.Field, field("api", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Api"))
+	_, err = FromRequest(req, []string{}, nil)
+	assert.EqualError(suite.T(), err, "field api of orders.Order: unresolved type google.protobuf.Api")
+}
+
+// versionedRequest returns a CodeGeneratorRequest laid out as buf does, with versioned proto packages and go_package
+// options naming their Go package.
+func versionedRequest() *pluginpb.CodeGeneratorRequest {
+	money := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("acme/common/v1/money.proto"),
+		Package: proto.String("acme.common.v1"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Money"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("cents", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+				},
+			},
+		},
+		Options: &descriptorpb.FileOptions{GoPackage: proto.String("github.com/acme/gen/common/v1;commonv1")},
+		Syntax:  proto.String("proto3"),
+	}
+
+	orders := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("acme/orders/v1/orders.proto"),
+		Package:    proto.String("acme.orders.v1"),
+		Dependency: []string{"google/protobuf/empty.proto", "acme/common/v1/money.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("total", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".acme.common.v1.Money"),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("OrderService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("PlaceOrder"),
+						InputType:  proto.String(".acme.orders.v1.Order"),
+						OutputType: proto.String(".google.protobuf.Empty"),
+					},
+				},
+			},
+		},
+		Options: &descriptorpb.FileOptions{GoPackage: proto.String("github.com/acme/gen/orders/v1;ordersv1")},
+		Syntax:  proto.String("proto3"),
+	}
+
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"acme/orders/v1/orders.proto"},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			{Name: proto.String("google/protobuf/empty.proto"), Package: proto.String("google.protobuf")},
+			money,
+			orders,
+		},
+	}
+}
+
+func (suite *EventBusTestSuite) TestFromRequestGoPackageName() {
+	req := versionedRequest()
+
+	tmpl, err := FromRequest(req, []string{}, nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "ordersv1", tmpl.Package)
+	assert.Equal(suite.T(), []string{"github.com/acme/gen/common/v1"}, tmpl.Imports)
+	assert.Equal(suite.T(), "commonv1.Money", tmpl.Structs[0].Attributes[0].Type)
+
+	content, err := render(tmpl)
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), string(content), "package ordersv1\n")
+
+	// without a name, the Go package is named after the last element of its import path
+	req.ProtoFile[2].Options.GoPackage = proto.String("github.com/acme/gen/orders/v1")
+	tmpl, err = FromRequest(req, []string{}, nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "v1", tmpl.Package)
+}
+
+func (suite *EventBusTestSuite) TestFromRequestVersionedPackage() {
+	req := versionedRequest()
+	req.ProtoFile[2].Options = nil
+
+	// without a go_package, the proto package is made a valid Go package name
+	tmpl, err := FromRequest(req, []string{}, nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "acme_orders_v1", tmpl.Package)
+	assert.Equal(suite.T(), "commonv1.Money", tmpl.Structs[0].Attributes[0].Type)
+
+	_, err = render(tmpl)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *EventBusTestSuite) TestRunPlugin() {
+	req := pluginRequest()
+	req.Parameter = proto.String("out=events.go,context=true")
+	data, err := proto.Marshal(req)
+	assert.Nil(suite.T(), err)
+
+	out := bytes.NewBuffer(nil)
+	assert.Nil(suite.T(), runPlugin(bytes.NewReader(data), out))
+
+	res := &pluginpb.CodeGeneratorResponse{}
+	assert.Nil(suite.T(), proto.Unmarshal(out.Bytes(), res))
+	assert.Empty(suite.T(), res.GetError())
+	assert.Len(suite.T(), res.File, 1)
+	assert.Equal(suite.T(), "events.go", res.File[0].GetName())
+	assert.Contains(suite.T(), res.File[0].GetContent(), "package orders")
+	assert.Contains(suite.T(), res.File[0].GetContent(), "PlaceOrder(context.Context, Order) error")
+
+	req.Parameter = proto.String("bogus=1")
+	data, err = proto.Marshal(req)
+	assert.Nil(suite.T(), err)
+
+	out.Reset()
+	assert.Nil(suite.T(), runPlugin(bytes.NewReader(data), out))
+	assert.Nil(suite.T(), proto.Unmarshal(out.Bytes(), res))
+	assert.Equal(suite.T(), "invalid plugin parameter bogus=1: unknown parameter", res.GetError())
+	assert.Empty(suite.T(), res.File)
+}
@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// protoFile is a parsed proto file, either an input file or one they import.
+type protoFile struct {
+	// name is the path of an input file, or the location of an imported one
+	name  string
+	proto *parser.Proto
+	pkg   string
+	// input files have their services generated
+	input bool
+	// goPackage is the Go package the types of the file are referenced from, instead of being generated
+	goPackage string
+	// goName is the name of the Go package the types of the file are generated in, or referenced from
+	goName string
+}
+
+func newProtoFile(name string, proto *parser.Proto, input bool) *protoFile {
+	file := &protoFile{
+		name:  name,
+		proto: proto,
+		input: input,
+	}
+
+	goPackage := ""
+	for _, body := range proto.ProtoBody {
+		switch b := body.(type) {
+		case *parser.Package:
+			file.pkg = b.Name
+		case *parser.Option:
+			if b.OptionName == "go_package" {
+				goPackage, _ = strconv.Unquote(b.Constant)
+			}
+		}
+	}
+
+	switch goPackage {
+	case "":
+		file.goName = goSanitized(file.pkg)
+	default:
+		file.goName = goPackageName(goPackage)
+	}
+	return file
+}
+
+// referenceFrom has the types of the file referenced from the Go package of a go_package option instead of generated.
+func (f *protoFile) referenceFrom(goPackage string) {
+	f.goPackage, _, _ = strings.Cut(goPackage, ";")
+	f.goName = goPackageName(goPackage)
+}
+
+// goPackageName returns the name of the Go package of a go_package option: the part after ";" when there is one,
+// otherwise the last element of its import path.
+func goPackageName(goPackage string) string {
+	imp, name, ok := strings.Cut(goPackage, ";")
+	if ok && name != "" {
+		return name
+	}
+	return goSanitized(path.Base(imp))
+}
+
+// goSanitized turns a package name into a valid Go identifier, replacing the characters it cannot contain, such as
+// the dots of acme.orders.v1, with underscores.
+func goSanitized(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	if token.IsKeyword(name) {
+		name += "_"
+	}
+	return name
+}
+
+// loader parses proto files and, recursively, the files they import.
+type loader struct {
+	protoPaths []string
+	goPackages map[string]string
+
+	// files are in the order they finished loading, so each file comes after the files it imports
+	files  []*protoFile
+	loaded map[string]*protoFile
+	// loading is the chain of imports being loaded, to detect cycles
+	loading []string
+}
+
+// Load returns the Template of the input files, parsing the files they import from the protoPaths, then from the
+// directory of the importing file.  Imported files listed in goPackages, keyed by import location, have their types
+// referenced from that Go package instead of generated.
+func Load(imports []string, files []string, protoPaths []string, goPackages map[string]string) (Template, error) {
+	l := loader{
+		protoPaths: protoPaths,
+		goPackages: goPackages,
+		loaded:     make(map[string]*protoFile),
+	}
+
+	for _, file := range files {
+		if err := l.load(file, file, true); err != nil {
+			return Template{Imports: imports}, err
+		}
+	}
+	return build(imports, l.files, true)
+}
+
+// load parses the file at path, imported as name, after the files it imports.
+func (l *loader) load(path, name string, input bool) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if file, ok := l.loaded[abs]; ok {
+		file.input = file.input || input
+		return nil
+	}
+
+	for i, loading := range l.loading {
+		if loading == abs {
+			chain := append(append([]string(nil), l.loading[i:]...), abs)
+			return fmt.Errorf("import cycle: %s", strings.Join(chain, " -> "))
+		}
+	}
+
+	fin, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fin.Close()
+
+	proto, err := protoparser.Parse(fin, protoparser.WithFilename(path))
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	l.loading = append(l.loading, abs)
+	defer func() {
+		l.loading = l.loading[:len(l.loading)-1]
+	}()
+
+	for _, body := range proto.ProtoBody {
+		imp, ok := body.(*parser.Import)
+		if !ok {
+			continue
+		}
+
+		location, err := strconv.Unquote(imp.Location)
+		if err != nil {
+			location = strings.Trim(imp.Location, `"'`)
+		}
+		// the well known types are mapped to Go types instead
+		if strings.HasPrefix(location, "google/protobuf/") {
+			continue
+		}
+
+		found, err := l.find(location, filepath.Dir(path))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := l.load(found, location, false); err != nil {
+			return err
+		}
+	}
+
+	file := newProtoFile(name, proto, input)
+	if imp, ok := l.goPackages[name]; ok && !input {
+		file.referenceFrom(imp)
+	}
+	l.loaded[abs] = file
+	l.files = append(l.files, file)
+	return nil
+}
+
+// find returns the path of the imported location, searching the proto paths then the directory of the importing file.
+func (l *loader) find(location, dir string) (string, error) {
+	for _, protoPath := range append(append([]string(nil), l.protoPaths...), dir) {
+		path := filepath.Join(protoPath, location)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("import %s not found in %s", location, strings.Join(append(append([]string(nil), l.protoPaths...), dir), ", "))
+}
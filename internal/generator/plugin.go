@@ -0,0 +1,470 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// RunPlugin runs as a protoc plugin, reading the CodeGeneratorRequest from in and writing the CodeGeneratorResponse
+// to out.  Logs are written to logs, as protoc reads the response from out.
+func RunPlugin(in io.Reader, out, logs io.Writer) error {
+	logger = zerolog.New(logs).With().Timestamp().Logger()
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	return runPlugin(in, out)
+}
+
+// runPlugin reads a CodeGeneratorRequest from in and writes the CodeGeneratorResponse to out, as a protoc plugin.
+// Errors in the proto files are reported in the response, for protoc to print them.
+func runPlugin(in io.Reader, out io.Writer) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		logger.Error().Err(err).Msg("error reading code generator request")
+		return err
+	}
+
+	req := &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		logger.Error().Err(err).Msg("error parsing code generator request")
+		return err
+	}
+
+	res := &pluginpb.CodeGeneratorResponse{
+		SupportedFeatures: proto.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)),
+	}
+	file, err := generate(req)
+	if err != nil {
+		res.Error = proto.String(err.Error())
+	} else {
+		res.File = append(res.File, file)
+	}
+
+	data, err = proto.Marshal(res)
+	if err != nil {
+		logger.Error().Err(err).Msg("error encoding code generator response")
+		return err
+	}
+
+	_, err = out.Write(data)
+	return err
+}
+
+// pluginParams are the comma separated key=value parameters given to the plugin, e.g.
+// --go-event-bus_opt=out=events.go,config=config.yaml
+type pluginParams struct {
+	out      string
+	config   string
+	context  bool
+	pointers bool
+}
+
+func parsePluginParams(parameter string) (pluginParams, error) {
+	params := pluginParams{
+		out: "bus.go",
+	}
+
+	for _, param := range strings.Split(parameter, ",") {
+		if param == "" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(param, "=")
+		var err error
+		switch key {
+		case "out":
+			params.out = value
+		case "config":
+			params.config = value
+		case "context":
+			params.context, err = strconv.ParseBool(value)
+		case "pointers":
+			params.pointers, err = strconv.ParseBool(value)
+		default:
+			err = fmt.Errorf("unknown parameter")
+		}
+
+		if err != nil {
+			return params, fmt.Errorf("invalid plugin parameter %s: %w", param, err)
+		}
+	}
+	return params, nil
+}
+
+// generate returns the bus generated for the files to generate of the request, next to the first of them.
+func generate(req *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse_File, error) {
+	params, err := parsePluginParams(req.GetParameter())
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := readConfig(params.config)
+	if err != nil {
+		return nil, err
+	}
+
+	tmplData, err := FromRequest(req, config.Imports, config.GoPackages)
+	if err != nil {
+		return nil, err
+	}
+	tmplData.Context = params.context
+	tmplData.Pointers = params.pointers
+	if err := tmplData.configure(config); err != nil {
+		return nil, err
+	}
+
+	formatted, err := render(tmplData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pluginpb.CodeGeneratorResponse_File{
+		Name:    proto.String(path.Join(path.Dir(req.FileToGenerate[0]), params.out)),
+		Content: proto.String(string(formatted)),
+	}, nil
+}
+
+// FromRequest returns the Template of the files to generate of a CodeGeneratorRequest, with the types of the files
+// they import.  Imported files listed in goPackages, or whose go_package differs from the one of the files to
+// generate, have their types referenced from that Go package instead of generated.
+func FromRequest(req *pluginpb.CodeGeneratorRequest, imports []string, goPackages map[string]string) (Template, error) {
+	if len(req.FileToGenerate) == 0 {
+		return Template{Imports: imports}, fmt.Errorf("no files to generate")
+	}
+
+	descriptors := make(map[string]*descriptorpb.FileDescriptorProto)
+	for _, fd := range req.ProtoFile {
+		descriptors[fd.GetName()] = fd
+	}
+
+	inputs := make(map[string]struct{})
+	goPackage := ""
+	for _, name := range req.FileToGenerate {
+		inputs[name] = struct{}{}
+		if fd, ok := descriptors[name]; ok && goPackage == "" {
+			goPackage = goImportPath(fd)
+		}
+	}
+
+	d := newDescriptors(req.ProtoFile)
+	var files []*protoFile
+	// protoc lists each file after the files it imports
+	for _, fd := range req.ProtoFile {
+		// the well known types are mapped to Go types instead
+		if strings.HasPrefix(fd.GetName(), "google/protobuf/") {
+			continue
+		}
+
+		parsed, err := d.convertFile(fd)
+		if err != nil {
+			return Template{Imports: imports}, fmt.Errorf("%s: %w", fd.GetName(), err)
+		}
+
+		_, input := inputs[fd.GetName()]
+		file := newProtoFile(fd.GetName(), parsed, input)
+		if fd.GetOptions().GetGoPackage() != "" {
+			file.goName = goPackageName(fd.GetOptions().GetGoPackage())
+		}
+		if !input {
+			if imp, ok := goPackages[fd.GetName()]; ok {
+				file.referenceFrom(imp)
+			} else if imp := goImportPath(fd); imp != "" && imp != goPackage {
+				file.referenceFrom(fd.GetOptions().GetGoPackage())
+			}
+		}
+		files = append(files, file)
+	}
+	return build(imports, files, true)
+}
+
+// goImportPath returns the import path of the go_package option of the file, without its package name.
+func goImportPath(fd *descriptorpb.FileDescriptorProto) string {
+	imp, _, _ := strings.Cut(fd.GetOptions().GetGoPackage(), ";")
+	return imp
+}
+
+// descriptors converts the compiled descriptors into the syntax tree parsed from proto files, so both go through the
+// same code generation.
+type descriptors struct {
+	// mapEntries are the messages generated for map fields, by full name with a leading dot
+	mapEntries map[string]*descriptorpb.DescriptorProto
+	// extensions are the custom options by extendee and field number
+	extensions map[string]map[int32]extension
+}
+
+// extension is a custom option, named by its full name, e.g. eventbus.workers
+type extension struct {
+	name string
+	typ  descriptorpb.FieldDescriptorProto_Type
+}
+
+func newDescriptors(files []*descriptorpb.FileDescriptorProto) descriptors {
+	d := descriptors{
+		mapEntries: make(map[string]*descriptorpb.DescriptorProto),
+		extensions: make(map[string]map[int32]extension),
+	}
+
+	var walk func(scope string, messages []*descriptorpb.DescriptorProto, extensions []*descriptorpb.FieldDescriptorProto)
+	walk = func(scope string, messages []*descriptorpb.DescriptorProto, extensions []*descriptorpb.FieldDescriptorProto) {
+		for _, ext := range extensions {
+			if d.extensions[ext.GetExtendee()] == nil {
+				d.extensions[ext.GetExtendee()] = make(map[int32]extension)
+			}
+			d.extensions[ext.GetExtendee()][ext.GetNumber()] = extension{
+				name: strings.TrimPrefix(scope+"."+ext.GetName(), "."),
+				typ:  ext.GetType(),
+			}
+		}
+		for _, m := range messages {
+			name := scope + "." + m.GetName()
+			if m.GetOptions().GetMapEntry() {
+				d.mapEntries[name] = m
+			}
+			walk(name, m.NestedType, m.Extension)
+		}
+	}
+	for _, fd := range files {
+		scope := ""
+		if fd.GetPackage() != "" {
+			scope = "." + fd.GetPackage()
+		}
+		walk(scope, fd.MessageType, fd.Extension)
+	}
+	return d
+}
+
+func (d descriptors) convertFile(fd *descriptorpb.FileDescriptorProto) (*parser.Proto, error) {
+	parsed := &parser.Proto{}
+	if fd.GetPackage() != "" {
+		parsed.ProtoBody = append(parsed.ProtoBody, &parser.Package{Name: fd.GetPackage()})
+	}
+
+	for _, e := range fd.EnumType {
+		parsed.ProtoBody = append(parsed.ProtoBody, convertEnum(e))
+	}
+
+	for _, m := range fd.MessageType {
+		message, err := d.convertMessage(m, fd.GetSyntax())
+		if err != nil {
+			return nil, err
+		}
+		parsed.ProtoBody = append(parsed.ProtoBody, message)
+	}
+
+	for _, s := range fd.Service {
+		service := &parser.Service{
+			ServiceName: s.GetName(),
+		}
+		for _, m := range s.Method {
+			options, err := d.options(".google.protobuf.MethodOptions", m.GetOptions())
+			if err != nil {
+				return nil, fmt.Errorf("rpc %s: %w", m.GetName(), err)
+			}
+
+			rpc := &parser.RPC{
+				RPCName:     m.GetName(),
+				RPCRequest:  &parser.RPCRequest{MessageType: typeName(m.GetInputType())},
+				RPCResponse: &parser.RPCResponse{MessageType: typeName(m.GetOutputType())},
+			}
+			for _, option := range options {
+				rpc.Options = append(rpc.Options, &parser.Option{OptionName: option.OptionName, Constant: option.Constant})
+			}
+			service.ServiceBody = append(service.ServiceBody, rpc)
+		}
+		parsed.ProtoBody = append(parsed.ProtoBody, service)
+	}
+	return parsed, nil
+}
+
+func (d descriptors) convertMessage(m *descriptorpb.DescriptorProto, syntax string) (*parser.Message, error) {
+	message := &parser.Message{
+		MessageName: m.GetName(),
+	}
+
+	oneofs := make(map[int32]*parser.Oneof)
+	for _, f := range m.Field {
+		fieldType, err := d.fieldType(f)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %s: %w", f.GetName(), m.GetName(), err)
+		}
+		options, err := d.options(".google.protobuf.FieldOptions", f.GetOptions())
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %s: %w", f.GetName(), m.GetName(), err)
+		}
+		number := strconv.Itoa(int(f.GetNumber()))
+
+		if entry, ok := d.mapEntries[f.GetTypeName()]; ok && f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+			key, err := d.fieldType(entry.Field[0])
+			if err != nil {
+				return nil, fmt.Errorf("field %s of %s: %w", f.GetName(), m.GetName(), err)
+			}
+			value, err := d.fieldType(entry.Field[1])
+			if err != nil {
+				return nil, fmt.Errorf("field %s of %s: %w", f.GetName(), m.GetName(), err)
+			}
+
+			message.MessageBody = append(message.MessageBody, &parser.MapField{
+				KeyType:      key,
+				Type:         value,
+				MapName:      f.GetName(),
+				FieldNumber:  number,
+				FieldOptions: options,
+			})
+			continue
+		}
+
+		if f.OneofIndex != nil && !f.GetProto3Optional() {
+			oneof, ok := oneofs[f.GetOneofIndex()]
+			if !ok {
+				oneof = &parser.Oneof{OneofName: m.OneofDecl[f.GetOneofIndex()].GetName()}
+				oneofs[f.GetOneofIndex()] = oneof
+				message.MessageBody = append(message.MessageBody, oneof)
+			}
+			oneof.OneofFields = append(oneof.OneofFields, &parser.OneofField{
+				Type:         fieldType,
+				FieldName:    f.GetName(),
+				FieldNumber:  number,
+				FieldOptions: options,
+			})
+			continue
+		}
+
+		message.MessageBody = append(message.MessageBody, &parser.Field{
+			IsRepeated:   f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED,
+			IsRequired:   f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REQUIRED,
+			IsOptional:   f.GetProto3Optional() || (syntax == "proto2" && f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL),
+			Type:         fieldType,
+			FieldName:    f.GetName(),
+			FieldNumber:  number,
+			FieldOptions: options,
+		})
+	}
+
+	for _, e := range m.EnumType {
+		message.MessageBody = append(message.MessageBody, convertEnum(e))
+	}
+
+	for _, nested := range m.NestedType {
+		if nested.GetOptions().GetMapEntry() {
+			continue
+		}
+		n, err := d.convertMessage(nested, syntax)
+		if err != nil {
+			return nil, err
+		}
+		message.MessageBody = append(message.MessageBody, n)
+	}
+	return message, nil
+}
+
+func convertEnum(e *descriptorpb.EnumDescriptorProto) *parser.Enum {
+	enum := &parser.Enum{
+		EnumName: e.GetName(),
+	}
+	for _, v := range e.Value {
+		enum.EnumBody = append(enum.EnumBody, &parser.EnumField{
+			Ident:  v.GetName(),
+			Number: strconv.Itoa(int(v.GetNumber())),
+		})
+	}
+	return enum
+}
+
+// fieldType returns the type of the field as written in a proto file.
+func (d descriptors) fieldType(f *descriptorpb.FieldDescriptorProto) (string, error) {
+	switch f.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		return typeName(f.GetTypeName()), nil
+	case descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return "", fmt.Errorf("unsupported group %s", f.GetTypeName())
+	default:
+		return strings.ToLower(strings.TrimPrefix(f.GetType().String(), "TYPE_")), nil
+	}
+}
+
+// typeName returns the full name of a type, which is absolute with a leading dot, except for the well known types
+// that are mapped by their name to Go types.
+func typeName(name string) string {
+	if strings.HasPrefix(name, ".google.protobuf.") {
+		return strings.TrimPrefix(name, ".")
+	}
+	return name
+}
+
+// options returns the custom options set on the options message, e.g. (eventbus.workers), with their value as it
+// would be written in a proto file.
+func (d descriptors) options(extendee string, options proto.Message) ([]*parser.FieldOption, error) {
+	if options == nil || !options.ProtoReflect().IsValid() {
+		return nil, nil
+	}
+
+	var parsed []*parser.FieldOption
+	unknown := options.ProtoReflect().GetUnknown()
+	for len(unknown) > 0 {
+		number, wireType, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+
+		var value []byte
+		var varint uint64
+		switch wireType {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(unknown)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(unknown)
+			varint = uint64(v)
+		case protowire.Fixed64Type:
+			varint, n = protowire.ConsumeFixed64(unknown)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(unknown)
+		default:
+			n = protowire.ConsumeFieldValue(number, wireType, unknown)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+
+		ext, ok := d.extensions[extendee][int32(number)]
+		if !ok {
+			continue
+		}
+
+		option := &parser.FieldOption{
+			OptionName: fmt.Sprintf("(%s)", ext.name),
+		}
+		switch ext.typ {
+		case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+			option.Constant = strconv.FormatBool(varint != 0)
+		case descriptorpb.FieldDescriptorProto_TYPE_DOUBLE:
+			option.Constant = strconv.FormatFloat(math.Float64frombits(varint), 'g', -1, 64)
+		case descriptorpb.FieldDescriptorProto_TYPE_FLOAT:
+			option.Constant = strconv.FormatFloat(float64(math.Float32frombits(uint32(varint))), 'g', -1, 32)
+		case descriptorpb.FieldDescriptorProto_TYPE_SINT32, descriptorpb.FieldDescriptorProto_TYPE_SINT64:
+			option.Constant = strconv.FormatInt(protowire.DecodeZigZag(varint), 10)
+		case descriptorpb.FieldDescriptorProto_TYPE_UINT32, descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+			descriptorpb.FieldDescriptorProto_TYPE_FIXED32, descriptorpb.FieldDescriptorProto_TYPE_FIXED64:
+			option.Constant = strconv.FormatUint(varint, 10)
+		case descriptorpb.FieldDescriptorProto_TYPE_SFIXED32:
+			option.Constant = strconv.FormatInt(int64(int32(varint)), 10)
+		case descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+			option.Constant = strconv.Quote(string(value))
+		default:
+			option.Constant = strconv.FormatInt(int64(varint), 10)
+		}
+		parsed = append(parsed, option)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,157 @@
+package dedup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type EventBusTestSuite struct {
+	suite.Suite
+	service *MockService
+}
+
+func (suite *EventBusTestSuite) SetupTest() {
+	suite.service = NewMockService(gomock.NewController(suite.T()))
+}
+
+func (suite *EventBusTestSuite) runDedup(bus *EventBus, events ...Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+	for _, event := range events {
+		assert.Nil(suite.T(), bus.PublishEvent(ctx, event))
+	}
+
+	_, err := bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestDedup() {
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(nil).Times(1)
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Gouda"}).Return(nil).Times(1)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{BufferSize: 4}
+		o.Dedup = &DedupOptions{}
+	})
+	suite.runDedup(bus,
+		Event{Data: HelloReply{Message: "Hello Cheddar"}},
+		Event{Data: HelloReply{Message: "Hello Cheddar"}},
+		Event{Data: HelloReply{Message: "Hello Gouda"}},
+		Event{Data: HelloReply{Message: "Hello Cheddar"}},
+	)
+}
+
+func (suite *EventBusTestSuite) TestDedupEnvelopeKey() {
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(nil).Times(1)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{BufferSize: 2}
+		o.Dedup = &DedupOptions{}
+	})
+	suite.runDedup(bus,
+		Event{IdempotencyKey: "cheese", Data: HelloReply{Message: "Hello Cheddar"}},
+		Event{IdempotencyKey: "cheese", Data: HelloReply{Message: "Hello Gouda"}},
+	)
+}
+
+func (suite *EventBusTestSuite) TestDedupWindow() {
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(nil).Times(1)
+
+	store := NewMemoryDedupStore(10)
+	assert.Nil(suite.T(), store.Put("HelloWorld/Hello Cheddar", time.Now().Add(-time.Hour)))
+
+	bus := NewEventBus(func(o *Options) {
+		o.Dedup = &DedupOptions{Store: store, Window: time.Minute}
+	})
+	suite.runDedup(bus, Event{Data: HelloReply{Message: "Hello Cheddar"}})
+
+	handled, ok, err := store.Get("HelloWorld/Hello Cheddar")
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), ok)
+	assert.WithinDuration(suite.T(), time.Now(), handled, time.Minute)
+}
+
+func (suite *EventBusTestSuite) TestDedupFailedEvent() {
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(fmt.Errorf("no cheese"))
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(nil)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{BufferSize: 3}
+		o.Dedup = &DedupOptions{}
+	})
+	suite.runDedup(bus,
+		Event{Data: HelloReply{Message: "Hello Cheddar"}},
+		Event{Data: HelloReply{Message: "Hello Cheddar"}},
+		Event{Data: HelloReply{Message: "Hello Cheddar"}},
+	)
+}
+
+func (suite *EventBusTestSuite) TestMemoryDedupStore() {
+	store := NewMemoryDedupStore(2)
+	now := time.Now()
+	assert.Nil(suite.T(), store.Put("cheddar", now))
+	assert.Nil(suite.T(), store.Put("gouda", now))
+
+	// using cheddar makes gouda the least recently used key
+	_, ok, _ := store.Get("cheddar")
+	assert.True(suite.T(), ok)
+	assert.Nil(suite.T(), store.Put("brie", now))
+
+	_, ok, _ = store.Get("gouda")
+	assert.False(suite.T(), ok)
+	handled, ok, _ := store.Get("cheddar")
+	assert.True(suite.T(), ok)
+	assert.True(suite.T(), now.Equal(handled))
+}
+
+func (suite *EventBusTestSuite) TestFileDedupStore() {
+	path := filepath.Join(suite.T().TempDir(), "dedup.jsonl")
+	now := time.Now()
+
+	store := NewFileDedupStore(path, 2)
+	for _, key := range []string{"cheddar", "gouda", "brie", "feta", "edam"} {
+		assert.Nil(suite.T(), store.Put(key, now))
+	}
+
+	// the file is compacted down to the remembered keys instead of growing with every key
+	fin, err := os.Open(path)
+	assert.Nil(suite.T(), err)
+	defer fin.Close()
+	lines := 0
+	scanner := bufio.NewScanner(fin)
+	for scanner.Scan() {
+		lines++
+	}
+	assert.LessOrEqual(suite.T(), lines, 4)
+
+	reopened := NewFileDedupStore(path, 2)
+	for key, remembered := range map[string]bool{"cheddar": false, "brie": false, "feta": true, "edam": true} {
+		_, ok, err := reopened.Get(key)
+		assert.Nil(suite.T(), err)
+		assert.Equal(suite.T(), remembered, ok, key)
+	}
+}
+
+func TestEventBusTestSuite(t *testing.T) {
+	suite.Run(t, new(EventBusTestSuite))
+}
@@ -0,0 +1,4 @@
+package dedup
+
+//go:generate go-event-bus-gen --in dedup.proto --out bus.go
+//go:generate mockgen -source=bus.go -destination mocks.go -package dedup
@@ -56,6 +56,26 @@ func (suite *EventBusTestSuite) TestExample() {
 	wg.Wait()
 }
 
+func (suite *EventBusTestSuite) TestTypedPublish() {
+	done := make(chan struct{})
+	suite.service.EXPECT().HandleEvent(events.CloudWatchEvent{Region: "us-west-2"}).DoAndReturn(func(events.CloudWatchEvent) error {
+		close(done)
+		return nil
+	})
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go bus.Run(ctx, suite.service)
+	bus.Ready()
+
+	err := bus.PublishEventsCloudWatchEvent(events.CloudWatchEvent{Region: "us-west-2"})
+	assert.Nil(suite.T(), err)
+	<-done
+}
+
 func TestEventBusTestSuite(t *testing.T) {
 	suite.Run(t, new(EventBusTestSuite))
 }
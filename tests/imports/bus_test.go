@@ -0,0 +1,105 @@
+package imports
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type EventBusTestSuite struct {
+	suite.Suite
+	service *MockService
+}
+
+func (suite *EventBusTestSuite) SetupTest() {
+	suite.service = NewMockService(gomock.NewController(suite.T()))
+}
+
+func (suite *EventBusTestSuite) TestExample() {
+	note := "leave at the door"
+	order := Order{
+		Id:       "1",
+		Total:    Money{Currency: EUR, Cents: 1250},
+		Ttl:      time.Hour,
+		Note:     &note,
+		Metadata: map[string]any{"channel": "web"},
+	}
+	charge := Charge{OrderId: "1", Amount: order.Total}
+	gomock.InOrder(
+		suite.service.EXPECT().PlaceOrder(order).Return(charge, nil),
+		suite.service.EXPECT().ChargeCard(charge).Return(nil),
+	)
+	suite.service.EXPECT().DescribeOrder(order).Return(&note, nil)
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	err := bus.PublishOrder(order)
+	assert.Nil(suite.T(), err)
+
+	_, err = bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestWellKnownTypeMethods() {
+	order := Order{Id: "1"}
+	metadata := map[string]any{"channel": "web"}
+	tagged := make(chan struct{})
+	gomock.InOrder(
+		suite.service.EXPECT().DescribeOrder(order).Return(nil, fmt.Errorf("no description")),
+		suite.service.EXPECT().TagOrder(metadata).DoAndReturn(func(map[string]any) error {
+			close(tagged)
+			return nil
+		}),
+	)
+	suite.service.EXPECT().PlaceOrder(gomock.Any()).Return(Charge{}, nil).AnyTimes()
+	suite.service.EXPECT().ChargeCard(gomock.Any()).Return(nil).AnyTimes()
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	description, err := bus.CallDescribeOrder(ctx, order)
+	assert.EqualError(suite.T(), err, "no description")
+	assert.Nil(suite.T(), description)
+
+	// well known types have no typed Publish method
+	assert.Nil(suite.T(), bus.Publish(metadata))
+	<-tagged
+
+	_, err = bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func TestEventBusTestSuite(t *testing.T) {
+	suite.Run(t, new(EventBusTestSuite))
+}
@@ -0,0 +1,4 @@
+package imports
+
+//go:generate go-event-bus-gen --in orders.proto --in payments.proto --proto_path protos --out bus.go
+//go:generate mockgen -source=bus.go -destination mocks.go -package imports
@@ -0,0 +1,89 @@
+package nested
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type EventBusTestSuite struct {
+	suite.Suite
+	service *MockService
+}
+
+func (suite *EventBusTestSuite) SetupTest() {
+	suite.service = NewMockService(gomock.NewController(suite.T()))
+}
+
+func (suite *EventBusTestSuite) TestExample() {
+	item := OrderItem{Sku: "cheddar", Quantity: 2}
+	shipment := Shipment{OrderId: "1", Item: item, Status: OrderStatus_SHIPPED}
+	gomock.InOrder(
+		suite.service.EXPECT().ShipItem(item).Return(shipment, nil),
+		suite.service.EXPECT().Track(shipment).Return(nil),
+	)
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	err := bus.PublishOrderItem(item)
+	assert.Nil(suite.T(), err)
+
+	_, err = bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestNestedTypes() {
+	order := Order{
+		Id:     "1",
+		Status: OrderStatus_PENDING,
+		Items:  []OrderItem{{Sku: "cheddar", Quantity: 2}},
+	}
+	assert.Equal(suite.T(), OrderStatusEnum(0), order.Status)
+	assert.Equal(suite.T(), "cheddar", order.Items[0].Sku)
+}
+
+func (suite *EventBusTestSuite) TestNestedEnumValues() {
+	// Order.Status and Payment.Status both declare PENDING, prefixed by their enum to keep them apart
+	payment := Payment{OrderId: "1", Status: PaymentStatus_PENDING}
+	assert.Equal(suite.T(), PaymentStatusEnum(0), payment.Status)
+	assert.Equal(suite.T(), PaymentStatusEnum(1), PaymentStatus_PAID)
+	assert.Equal(suite.T(), OrderStatusEnum(0), OrderStatus_PENDING)
+}
+
+func (suite *EventBusTestSuite) TestMaps() {
+	order := Order{
+		Id:         "1",
+		ItemsBySku: map[string]OrderItem{"cheddar": {Sku: "cheddar", Quantity: 2}},
+		Statuses:   map[int32]OrderStatusEnum{1: OrderStatus_SHIPPED},
+	}
+
+	data, err := json.Marshal(order)
+	assert.Nil(suite.T(), err)
+
+	var decoded Order
+	assert.Nil(suite.T(), json.Unmarshal(data, &decoded))
+	assert.Equal(suite.T(), order, decoded)
+}
+
+func TestEventBusTestSuite(t *testing.T) {
+	suite.Run(t, new(EventBusTestSuite))
+}
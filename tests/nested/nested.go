@@ -0,0 +1,4 @@
+package nested
+
+//go:generate go-event-bus-gen --in nested.proto --out bus.go
+//go:generate mockgen -source=bus.go -destination mocks.go -package nested
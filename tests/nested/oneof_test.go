@@ -0,0 +1,64 @@
+package nested
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *EventBusTestSuite) TestOneofJSON() {
+	for _, notification := range []Notification{
+		{Id: "1", Channel: NotificationEmail{Email: Email{Address: "cheddar@example.com"}}},
+		{Id: "2", Channel: NotificationSms{Sms: "555-0100"}},
+		{Id: "3", Channel: NotificationStatus{Status: OrderStatus_SHIPPED}},
+		{Id: "4"},
+	} {
+		data, err := json.Marshal(notification)
+		assert.Nil(suite.T(), err)
+
+		var decoded Notification
+		assert.Nil(suite.T(), json.Unmarshal(data, &decoded))
+		assert.Equal(suite.T(), notification, decoded)
+	}
+
+	data, err := json.Marshal(Notification{Id: "1", Channel: NotificationSms{Sms: "555-0100"}})
+	assert.Nil(suite.T(), err)
+	assert.JSONEq(suite.T(), `{"id": "1", "sms": "555-0100"}`, string(data))
+}
+
+func (suite *EventBusTestSuite) TestOneofRouting() {
+	notification := Notification{Id: "1", Channel: NotificationEmail{Email: Email{Address: "cheddar@example.com"}}}
+	suite.service.EXPECT().Notify(notification).Return(nil)
+	suite.service.EXPECT().SendEmail(NotificationEmail{Email: Email{Address: "cheddar@example.com"}}).Return(nil)
+
+	bus := NewEventBus()
+	c := make(chan Event, 1)
+	bus.Subscribe("NotificationEmail", c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	err := bus.PublishNotification(notification)
+	assert.Nil(suite.T(), err)
+
+	_, err = bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+
+	// the case is published as an event caused by the notification
+	event := <-c
+	assert.NotEmpty(suite.T(), event.CausationID)
+	assert.Equal(suite.T(), event.CorrelationID, event.CausationID)
+}
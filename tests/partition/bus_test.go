@@ -0,0 +1,160 @@
+package partition
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type EventBusTestSuite struct {
+	suite.Suite
+	service *MockService
+}
+
+func (suite *EventBusTestSuite) SetupTest() {
+	suite.service = NewMockService(gomock.NewController(suite.T()))
+}
+
+// concurrency records the most handlers running at once
+type concurrency struct {
+	lock    sync.Mutex
+	running int
+	max     int
+}
+
+func (c *concurrency) handle(hold time.Duration) {
+	c.lock.Lock()
+	c.running++
+	if c.running > c.max {
+		c.max = c.running
+	}
+	c.lock.Unlock()
+
+	time.Sleep(hold)
+
+	c.lock.Lock()
+	c.running--
+	c.lock.Unlock()
+}
+
+func (c *concurrency) Max() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.max
+}
+
+func (suite *EventBusTestSuite) TestPartitionKey() {
+	key, ok := partitionKey(HelloRequest{Name: "Cheddar"})
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "Cheddar", key)
+
+	_, ok = partitionKey(HelloReply{Message: "Hello Cheddar"})
+	assert.False(suite.T(), ok)
+
+	key, ok = Event{Key: "cheese", Data: HelloRequest{Name: "Cheddar"}}.partitionKey()
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "cheese", key)
+}
+
+func (suite *EventBusTestSuite) TestPartitionedWorkers() {
+	var lock sync.Mutex
+	running := make(map[string]int)
+	overlapping := make(map[string]bool)
+	var c concurrency
+	suite.service.EXPECT().SayHello(gomock.Any()).DoAndReturn(func(req HelloRequest) (HelloReply, error) {
+		lock.Lock()
+		running[req.Name]++
+		if running[req.Name] > 1 {
+			overlapping[req.Name] = true
+		}
+		lock.Unlock()
+
+		c.handle(20 * time.Millisecond)
+
+		lock.Lock()
+		running[req.Name]--
+		lock.Unlock()
+		return HelloReply{}, nil
+	}).Times(8)
+	suite.service.EXPECT().HelloWorld(HelloReply{}).Return(nil).Times(8)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{BufferSize: 8}
+		o.MethodWorkers = map[string]int{"SayHello": 4}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+	for i := 0; i < 8; i++ {
+		assert.Nil(suite.T(), bus.Publish(HelloRequest{Name: []string{"Cheddar", "Gouda"}[i%2]}))
+	}
+
+	_, err := bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+
+	assert.Empty(suite.T(), overlapping)
+	assert.Equal(suite.T(), 2, c.Max())
+}
+
+func (suite *EventBusTestSuite) TestPublishWithKeyOrder() {
+	var lock sync.Mutex
+	var handled []string
+	suite.service.EXPECT().HelloWorld(gomock.Any()).DoAndReturn(func(reply HelloReply) error {
+		// later events finish sooner, so any of them overtaking the others would show up in the handling order
+		n, _ := strconv.Atoi(reply.Message)
+		time.Sleep(time.Duration(6-n) * 5 * time.Millisecond)
+		lock.Lock()
+		handled = append(handled, reply.Message)
+		lock.Unlock()
+		return nil
+	}).Times(6)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{BufferSize: 6}
+		o.MethodWorkers = map[string]int{"HelloWorld": 3}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+	var published []string
+	for i := 0; i < 6; i++ {
+		published = append(published, fmt.Sprint(i))
+		assert.Nil(suite.T(), bus.PublishWithKey(ctx, "cheddar", HelloReply{Message: fmt.Sprint(i)}))
+	}
+
+	_, err := bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+
+	assert.Equal(suite.T(), published, handled)
+}
+
+func TestEventBusTestSuite(t *testing.T) {
+	suite.Run(t, new(EventBusTestSuite))
+}
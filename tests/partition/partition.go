@@ -0,0 +1,4 @@
+package partition
+
+//go:generate go-event-bus-gen --in partition.proto --out bus.go
+//go:generate mockgen -source=bus.go -destination mocks.go -package partition
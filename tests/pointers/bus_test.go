@@ -0,0 +1,112 @@
+package pointers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type EventBusTestSuite struct {
+	suite.Suite
+	service *MockService
+}
+
+func (suite *EventBusTestSuite) SetupTest() {
+	suite.service = NewMockService(gomock.NewController(suite.T()))
+}
+
+func (suite *EventBusTestSuite) TestExample() {
+	gomock.InOrder(
+		suite.service.EXPECT().SayHello(&HelloRequest{Name: "Cheddar"}).Return(&HelloReply{Message: "Hello Cheddar"}, nil),
+		suite.service.EXPECT().HelloWorld(&HelloReply{Message: "Hello Cheddar"}).Return(nil),
+	)
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := bus.Run(ctx, suite.service); err != nil {
+			panic(err)
+		}
+	}()
+
+	bus.Ready()
+
+	err := bus.PublishHelloRequest(&HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestNilOutput() {
+	suite.service.EXPECT().SayHello(&HelloRequest{Name: "Cheddar"}).Return(nil, nil)
+
+	bus := NewEventBus()
+	c := make(chan Event, 1)
+	bus.Subscribe("HelloReply", c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+
+	_, err = bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+	assert.Len(suite.T(), c, 0)
+}
+
+func (suite *EventBusTestSuite) TestCall() {
+	suite.service.EXPECT().SayHello(&HelloRequest{Name: "Cheddar"}).Return(&HelloReply{Message: "Hello Cheddar"}, nil)
+	suite.service.EXPECT().SayHello(&HelloRequest{Name: "Gouda"}).Return(nil, nil)
+	suite.service.EXPECT().HelloWorld(&HelloReply{Message: "Hello Cheddar"}).Return(nil)
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	reply, err := bus.CallSayHello(ctx, &HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &HelloReply{Message: "Hello Cheddar"}, reply)
+
+	reply, err = bus.CallSayHello(ctx, &HelloRequest{Name: "Gouda"})
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), reply)
+
+	_, err = bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func TestEventBusTestSuite(t *testing.T) {
+	suite.Run(t, new(EventBusTestSuite))
+}
@@ -0,0 +1,4 @@
+package pointers
+
+//go:generate go-event-bus-gen --in pointers.proto --out bus.go --pointers
+//go:generate mockgen -source=bus.go -destination mocks.go -package pointers
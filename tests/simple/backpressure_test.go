@@ -0,0 +1,221 @@
+package simple
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *EventBusTestSuite) TestBackpressureError() {
+	bus := NewEventBus(func(o *Options) {
+		o.Queues = map[string]QueueOptions{
+			"HelloRequest": {Backpressure: BackpressureError},
+		}
+	})
+
+	c := make(chan Event, 1)
+	bus.Subscribe("HelloRequest", c)
+
+	assert.Nil(suite.T(), bus.Publish(HelloRequest{Name: "Cheddar"}))
+	err := bus.Publish(HelloRequest{Name: "Gouda"})
+	assert.True(suite.T(), errors.Is(err, ErrQueueFull))
+	assert.Equal(suite.T(), HelloRequest{Name: "Cheddar"}, (<-c).Data)
+}
+
+func (suite *EventBusTestSuite) TestBackpressureBlockWithTimeout() {
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{
+			Backpressure: BackpressureBlockWithTimeout,
+			Timeout:      10 * time.Millisecond,
+		}
+	})
+
+	c := make(chan Event)
+	bus.Subscribe("HelloRequest", c)
+
+	err := bus.Publish(HelloRequest{Name: "Cheddar"})
+	assert.True(suite.T(), errors.Is(err, ErrQueueFull))
+}
+
+func (suite *EventBusTestSuite) TestBackpressureBlockWithDefaultTimeout() {
+	bus := NewEventBus(func(o *Options) {
+		o.Queues = map[string]QueueOptions{
+			"HelloRequest": {Backpressure: BackpressureBlockWithTimeout},
+		}
+	})
+
+	c := make(chan Event)
+	bus.Subscribe("HelloRequest", c)
+
+	received := make(chan Event, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		received <- <-c
+	}()
+
+	// without a Timeout the publish still waits for room instead of failing at once
+	assert.Nil(suite.T(), bus.Publish(HelloRequest{Name: "Cheddar"}))
+	assert.Equal(suite.T(), HelloRequest{Name: "Cheddar"}, (<-received).Data)
+}
+
+func (suite *EventBusTestSuite) TestBackpressureDropNewest() {
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{
+			Backpressure: BackpressureDropNewest,
+		}
+	})
+
+	c := make(chan Event, 1)
+	bus.Subscribe("HelloRequest", c)
+
+	assert.Nil(suite.T(), bus.Publish(HelloRequest{Name: "Cheddar"}))
+	assert.Nil(suite.T(), bus.Publish(HelloRequest{Name: "Gouda"}))
+	assert.Equal(suite.T(), HelloRequest{Name: "Cheddar"}, (<-c).Data)
+	assert.Empty(suite.T(), c)
+}
+
+func (suite *EventBusTestSuite) TestBackpressureDropOldest() {
+	release := make(chan struct{})
+	last := make(chan struct{})
+	var lock sync.Mutex
+	var handled []string
+	suite.service.EXPECT().HelloWorld(gomock.Any()).DoAndReturn(func(reply HelloReply) error {
+		<-release
+		lock.Lock()
+		defer lock.Unlock()
+		handled = append(handled, reply.Message)
+		if reply.Message == "9" {
+			close(last)
+		}
+		return nil
+	}).AnyTimes()
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queues = map[string]QueueOptions{
+			"HelloReply": {
+				BufferSize:   1,
+				Backpressure: BackpressureDropOldest,
+			},
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	for i := 0; i < 10; i++ {
+		assert.Nil(suite.T(), bus.Publish(HelloReply{Message: fmt.Sprint(i)}))
+	}
+
+	close(release)
+	<-last
+	cancel()
+	wg.Wait()
+
+	assert.Contains(suite.T(), handled, "9")
+	assert.Less(suite.T(), len(handled), 10)
+}
+
+func (suite *EventBusTestSuite) TestBackpressureDropOldestUnbuffered() {
+	busy := make(chan struct{}, 1)
+	release := make(chan struct{})
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		select {
+		case busy <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	}).MinTimes(1)
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Gouda"}).Times(0)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{Backpressure: BackpressureDropOldest}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+	// the unbuffered queue drops events until the worker is waiting for them
+	assert.Eventually(suite.T(), func() bool {
+		assert.Nil(suite.T(), bus.Publish(HelloReply{Message: "Hello Cheddar"}))
+		select {
+		case <-busy:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+
+	// with the worker busy there is no queued event to drop, so the newest one is dropped instead of blocking
+	published := make(chan error, 1)
+	go func() {
+		published <- bus.Publish(HelloReply{Message: "Hello Gouda"})
+	}()
+	select {
+	case err := <-published:
+		assert.Nil(suite.T(), err)
+	case <-time.After(time.Second):
+		suite.T().Fatal("publish blocked on an unbuffered queue")
+	}
+
+	close(release)
+	_, err := bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestPublishAsync() {
+	handled := make(chan struct{})
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		close(handled)
+		return nil
+	})
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	future := bus.PublishAsync(HelloReply{Message: "Hello Cheddar"})
+	assert.Nil(suite.T(), future.Wait(ctx))
+	<-future.Done()
+	assert.Nil(suite.T(), future.Err())
+	<-handled
+
+	future = bus.PublishAsync("Hello Cheddar")
+	assert.NotNil(suite.T(), future.Wait(ctx))
+
+	cancel()
+	wg.Wait()
+}
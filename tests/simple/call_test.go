@@ -0,0 +1,85 @@
+package simple
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *EventBusTestSuite) TestCall() {
+	done := make(chan struct{})
+	suite.service.EXPECT().SayHello(HelloRequest{Name: "Cheddar"}).Return(HelloReply{Message: "Hello Cheddar"}, nil)
+	suite.service.EXPECT().SayHello(HelloRequest{Name: "Gouda"}).Return(HelloReply{}, Permanent(fmt.Errorf("unknown cheese")))
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		close(done)
+		return nil
+	})
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	reply, err := bus.CallSayHello(ctx, HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), HelloReply{Message: "Hello Cheddar"}, reply)
+	// the reply is still published to HelloWorld
+	<-done
+
+	_, err = bus.CallSayHello(ctx, HelloRequest{Name: "Gouda"})
+	assert.EqualError(suite.T(), err, "unknown cheese")
+
+	cancel()
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestCallTimeout() {
+	release := make(chan struct{})
+	suite.service.EXPECT().SayHello(HelloRequest{Name: "Cheddar"}).DoAndReturn(func(HelloRequest) (HelloReply, error) {
+		<-release
+		return HelloReply{}, nil
+	})
+	suite.service.EXPECT().HelloWorld(HelloReply{}).Return(nil).AnyTimes()
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	callCtx, callCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer callCancel()
+	_, err := bus.CallSayHello(callCtx, HelloRequest{Name: "Cheddar"})
+	assert.ErrorIs(suite.T(), err, context.DeadlineExceeded)
+
+	close(release)
+	_, err = bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestCallNotRunning() {
+	bus := NewEventBus()
+	_, err := bus.CallSayHello(context.Background(), HelloRequest{Name: "Cheddar"})
+	assert.EqualError(suite.T(), err, "method SayHello is not running")
+}
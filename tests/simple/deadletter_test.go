@@ -0,0 +1,209 @@
+package simple
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *EventBusTestSuite) TestDeadLetter() {
+	failed := make(chan struct{})
+	done := make(chan struct{})
+	gomock.InOrder(
+		suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(fmt.Errorf("throttled")),
+		suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+			close(failed)
+			return fmt.Errorf("throttled")
+		}),
+		suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+			close(done)
+			return nil
+		}),
+	)
+
+	bus := NewEventBus(func(o *Options) {
+		o.DeadLetter = NewMemoryDeadLetterSink()
+		o.Retry = &RetryPolicy{
+			MaxAttempts: 2,
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloReply{Message: "Hello Cheddar"})
+	assert.Nil(suite.T(), err)
+
+	<-failed
+	var letters []DeadLetter
+	assert.Eventually(suite.T(), func() bool {
+		letters, err = bus.DeadLetters()
+		return err == nil && len(letters) == 1
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(suite.T(), "HelloReply", letters[0].Type)
+	assert.Equal(suite.T(), "HelloWorld", letters[0].Method)
+	assert.Equal(suite.T(), HelloReply{Message: "Hello Cheddar"}, letters[0].Data)
+	assert.Equal(suite.T(), "HelloWorld failed after 2 attempts: throttled", letters[0].Error)
+	assert.Equal(suite.T(), 2, letters[0].Attempts)
+	assert.NotEmpty(suite.T(), letters[0].EventID)
+	assert.Equal(suite.T(), letters[0].EventID, letters[0].CorrelationID)
+
+	assert.Nil(suite.T(), bus.Republish(letters[0].ID))
+	<-done
+
+	letters, err = bus.DeadLetters()
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), letters)
+	assert.EqualError(suite.T(), bus.Republish("missing"), "dead letter missing not found")
+
+	cancel()
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestFileDeadLetterSink() {
+	sink := NewFileDeadLetterSink(filepath.Join(suite.T().TempDir(), "dead_letters.jsonl"))
+
+	letters, err := sink.List()
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), letters)
+
+	now := time.Now().UTC()
+	first := DeadLetter{
+		ID:       "1",
+		Type:     "HelloRequest",
+		Method:   "SayHello",
+		Data:     HelloRequest{Name: "Cheddar"},
+		Error:    "throttled",
+		Attempts: 3,
+		Time:     now,
+	}
+	second := DeadLetter{
+		ID:       "2",
+		Type:     "HelloReply",
+		Method:   "HelloWorld",
+		Data:     HelloReply{Message: "Hello Cheddar"},
+		Error:    "received invalid event type",
+		Attempts: 1,
+		Time:     now,
+	}
+
+	assert.Nil(suite.T(), sink.Put(first))
+	assert.Nil(suite.T(), sink.Put(second))
+
+	letters, err = sink.List()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []DeadLetter{first, second}, letters)
+
+	assert.Nil(suite.T(), sink.Delete("1"))
+	assert.EqualError(suite.T(), sink.Delete("1"), "dead letter 1 not found")
+
+	letters, err = sink.List()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []DeadLetter{second}, letters)
+}
+
+func (suite *EventBusTestSuite) TestRepublishKeepsLetterUntilDelivered() {
+	busy := make(chan struct{})
+	release := make(chan struct{})
+	gomock.InOrder(
+		suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(fmt.Errorf("throttled")),
+		suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Swiss"}).DoAndReturn(func(HelloReply) error {
+			close(busy)
+			<-release
+			return nil
+		}),
+		suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(nil),
+	)
+
+	republished := make(chan Event, 1)
+	bus := NewEventBus(func(o *Options) {
+		o.DeadLetter = NewMemoryDeadLetterSink()
+		o.Queue = QueueOptions{Backpressure: BackpressureError}
+		o.Interceptors = []Interceptor{
+			func(ctx context.Context, data any, info HandlerInfo, next HandlerFunc) (any, error) {
+				event, _ := EventFromContext(ctx)
+				if event.ID == "original" && info.Method == "HelloWorld" {
+					select {
+					case republished <- event:
+					default:
+					}
+				}
+				return next(ctx, data)
+			},
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	original := Event{
+		ID:            "original",
+		CorrelationID: "correlation",
+		Headers:       map[string]string{"tenant": "cheese"},
+		Data:          HelloReply{Message: "Hello Cheddar"},
+	}
+	// the unbuffered queue refuses events until the worker is waiting for them
+	assert.Eventually(suite.T(), func() bool {
+		return bus.PublishEvent(ctx, original) == nil
+	}, time.Second, time.Millisecond)
+	// the first, failed, handling is seen by the interceptor too
+	<-republished
+
+	var letters []DeadLetter
+	assert.Eventually(suite.T(), func() bool {
+		var err error
+		letters, err = bus.DeadLetters()
+		return err == nil && len(letters) == 1
+	}, time.Second, time.Millisecond)
+
+	assert.Eventually(suite.T(), func() bool {
+		return bus.Publish(HelloReply{Message: "Hello Swiss"}) == nil
+	}, time.Second, time.Millisecond)
+	<-busy
+
+	assert.ErrorIs(suite.T(), bus.Republish(letters[0].ID), ErrQueueFull)
+	remaining, err := bus.DeadLetters()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), letters, remaining)
+
+	close(release)
+	assert.Eventually(suite.T(), func() bool {
+		return bus.Republish(letters[0].ID) == nil
+	}, time.Second, time.Millisecond)
+
+	event := <-republished
+	assert.Equal(suite.T(), "correlation", event.CorrelationID)
+	assert.Equal(suite.T(), map[string]string{"tenant": "cheese"}, event.Headers)
+
+	remaining, err = bus.DeadLetters()
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), remaining)
+
+	_, err = bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
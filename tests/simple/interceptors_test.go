@@ -0,0 +1,129 @@
+package simple
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type intercepted struct {
+	info   HandlerInfo
+	input  any
+	output any
+}
+
+func (suite *EventBusTestSuite) TestInterceptors() {
+	suite.service.EXPECT().SayHello(HelloRequest{Name: "Cheddar"}).Return(HelloReply{Message: "Hello Cheddar"}, nil)
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(nil)
+
+	var lock sync.Mutex
+	var order []string
+	var calls []intercepted
+	var published []string
+	done := make(chan struct{})
+
+	bus := NewEventBus(func(o *Options) {
+		o.Interceptors = []Interceptor{
+			func(ctx context.Context, data any, info HandlerInfo, next HandlerFunc) (any, error) {
+				lock.Lock()
+				order = append(order, "outer")
+				lock.Unlock()
+				return next(ctx, data)
+			},
+			func(ctx context.Context, data any, info HandlerInfo, next HandlerFunc) (any, error) {
+				lock.Lock()
+				order = append(order, "inner")
+				lock.Unlock()
+
+				out, err := next(ctx, data)
+
+				lock.Lock()
+				calls = append(calls, intercepted{info: info, input: data, output: out})
+				if len(calls) == 2 {
+					close(done)
+				}
+				lock.Unlock()
+				return out, err
+			},
+		}
+		o.PublishInterceptors = []PublishInterceptor{
+			func(ctx context.Context, data any, info PublishInfo, next PublishFunc) error {
+				lock.Lock()
+				published = append(published, info.EventType)
+				lock.Unlock()
+				return next(ctx, data)
+			},
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := bus.Run(ctx, suite.service); err != nil {
+			panic(err)
+		}
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+
+	<-done
+	cancel()
+	wg.Wait()
+
+	assert.Equal(suite.T(), []string{"outer", "inner", "outer", "inner"}, order)
+	assert.Equal(suite.T(), []string{"HelloRequest", "HelloReply"}, published)
+	assert.Equal(suite.T(), []intercepted{
+		{
+			info:   HandlerInfo{EventType: "HelloRequest", Method: "SayHello"},
+			input:  HelloRequest{Name: "Cheddar"},
+			output: HelloReply{Message: "Hello Cheddar"},
+		},
+		{
+			info:  HandlerInfo{EventType: "HelloReply", Method: "HelloWorld"},
+			input: HelloReply{Message: "Hello Cheddar"},
+		},
+	}, calls)
+}
+
+func (suite *EventBusTestSuite) TestInterceptorShortCircuit() {
+	done := make(chan struct{})
+
+	bus := NewEventBus(func(o *Options) {
+		o.Interceptors = []Interceptor{
+			func(ctx context.Context, data any, info HandlerInfo, next HandlerFunc) (any, error) {
+				defer close(done)
+				return nil, nil
+			},
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := bus.Run(ctx, suite.service); err != nil {
+			panic(err)
+		}
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+
+	<-done
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,56 @@
+package simple
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *EventBusTestSuite) TestPanicRecovery() {
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		panic("boom")
+	})
+
+	hooked := make(chan *PanicError, 1)
+	bus := NewEventBus(func(o *Options) {
+		strict := true
+		o.Strict = &strict
+		o.Retry = &RetryPolicy{
+			MaxAttempts: 3,
+		}
+		o.DeadLetter = NewMemoryDeadLetterSink()
+		o.OnPanic = func(info HandlerInfo, data any, err *PanicError) {
+			assert.Equal(suite.T(), HandlerInfo{EventType: "HelloReply", Method: "HelloWorld"}, info)
+			assert.Equal(suite.T(), HelloReply{Message: "Hello Cheddar"}, data)
+			hooked <- err
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errs := make(chan error)
+	go func() {
+		errs <- bus.Run(ctx, suite.service)
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloReply{Message: "Hello Cheddar"})
+	assert.Nil(suite.T(), err)
+
+	err = <-errs
+	var panicErr *PanicError
+	assert.True(suite.T(), errors.As(err, &panicErr))
+	assert.Equal(suite.T(), "HelloWorld", panicErr.Method)
+	assert.Equal(suite.T(), "boom", panicErr.Value)
+	assert.Contains(suite.T(), string(panicErr.Stack), "panic")
+	assert.Equal(suite.T(), panicErr, <-hooked)
+
+	letters, err := bus.DeadLetters()
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), letters, 1)
+	assert.Equal(suite.T(), 1, letters[0].Attempts)
+}
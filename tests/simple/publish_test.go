@@ -0,0 +1,58 @@
+package simple
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *EventBusTestSuite) TestTypedPublish() {
+	suite.service.EXPECT().SayHello(HelloRequest{Name: "Cheddar"}).Return(HelloReply{Message: "Hello Cheddar"}, nil)
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(nil)
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Gouda"}).Return(nil)
+
+	bus := NewEventBus()
+	c := make(chan Event, 3)
+	bus.Subscribe("HelloReply", c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+	assert.Nil(suite.T(), bus.PublishHelloRequest(HelloRequest{Name: "Cheddar"}))
+	assert.Equal(suite.T(), HelloReply{Message: "Hello Cheddar"}, (<-c).Data)
+	assert.Nil(suite.T(), bus.PublishHelloReply(HelloReply{Message: "Hello Gouda"}))
+	assert.Equal(suite.T(), HelloReply{Message: "Hello Gouda"}, (<-c).Data)
+
+	_, err := bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestPublishPointer() {
+	bus := NewEventBus()
+	c := make(chan Event, 1)
+	bus.Subscribe("HelloRequest", c)
+
+	err := bus.Publish(&HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), HelloRequest{Name: "Cheddar"}, (<-c).Data)
+
+	var missing *HelloRequest
+	assert.EqualError(suite.T(), bus.Publish(missing), "invalid type provided: *simple.HelloRequest")
+}
+
+func (suite *EventBusTestSuite) TestPublishInvalidType() {
+	bus := NewEventBus()
+	assert.EqualError(suite.T(), bus.Publish("Cheddar"), "invalid type provided: string")
+	assert.EqualError(suite.T(), bus.Publish(struct{ Name string }{}), "invalid type provided: struct { Name string }")
+}
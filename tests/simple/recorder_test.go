@@ -0,0 +1,110 @@
+package simple
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *EventBusTestSuite) TestRecordReplay() {
+	done := make(chan struct{})
+	suite.service.EXPECT().SayHello(HelloRequest{Name: "Cheddar"}).Return(HelloReply{Message: "Hello Cheddar"}, nil).Times(2)
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		done <- struct{}{}
+		return nil
+	}).Times(2)
+
+	var log bytes.Buffer
+	recorder := NewRecorder(&log)
+	bus := NewEventBus(func(o *Options) {
+		o.PublishInterceptors = []PublishInterceptor{recorder.Intercept}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+	err := bus.PublishEvent(ctx, Event{Headers: map[string]string{"tenant": "cheddar"}, Data: HelloRequest{Name: "Cheddar"}})
+	assert.Nil(suite.T(), err)
+	<-done
+
+	_, err = bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	assert.Len(suite.T(), lines, 2)
+	request, err := JSONCodec{}.Unmarshal([]byte(lines[0]))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), HelloRequest{Name: "Cheddar"}, request.Data)
+	reply, err := JSONCodec{}.Unmarshal([]byte(lines[1]))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), HelloReply{Message: "Hello Cheddar"}, reply.Data)
+	assert.Equal(suite.T(), request.ID, reply.CausationID)
+
+	// The caused HelloReply is published again by SayHello rather than replayed
+	replayed := NewEventBus()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), replayed.Run(ctx, suite.service))
+	}()
+
+	replayed.Ready()
+	err = replayed.Replay(ctx, strings.NewReader(log.String()))
+	assert.Nil(suite.T(), err)
+	<-done
+
+	_, err = replayed.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestReplayOptions() {
+	start := time.Now()
+	var log bytes.Buffer
+	for i, event := range []Event{
+		{ID: "1", Time: start, Data: HelloRequest{Name: "Cheddar"}},
+		{ID: "2", Time: start.Add(100 * time.Millisecond), CausationID: "1", Data: HelloReply{Message: "Hello Cheddar"}},
+		{ID: "3", Time: start.Add(200 * time.Millisecond), Data: HelloReply{Message: "Hello Gouda"}},
+	} {
+		event.Type = []string{"HelloRequest", "HelloReply", "HelloReply"}[i]
+		line, err := JSONCodec{}.Marshal(event)
+		assert.Nil(suite.T(), err)
+		log.Write(append(line, '\n'))
+	}
+
+	bus := NewEventBus()
+	c := make(chan Event, 3)
+	bus.Subscribe("HelloRequest", c)
+	bus.Subscribe("HelloReply", c)
+
+	began := time.Now()
+	err := bus.Replay(context.Background(), bytes.NewReader(log.Bytes()), func(o *ReplayOptions) {
+		o.Speed = 2
+		o.Types = []string{"HelloReply"}
+		o.Caused = true
+	})
+	assert.Nil(suite.T(), err)
+	elapsed := time.Since(began)
+
+	assert.Equal(suite.T(), "2", (<-c).ID)
+	assert.Equal(suite.T(), "3", (<-c).ID)
+	assert.Len(suite.T(), c, 0)
+	assert.GreaterOrEqual(suite.T(), elapsed, 50*time.Millisecond)
+	assert.Less(suite.T(), elapsed, 100*time.Millisecond)
+
+	err = bus.Replay(context.Background(), strings.NewReader(`{"type":"Unknown","data":{}}`))
+	assert.EqualError(suite.T(), err, "line 1: unknown event type Unknown")
+}
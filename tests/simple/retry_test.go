@@ -0,0 +1,121 @@
+package simple
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *EventBusTestSuite) TestRetry() {
+	done := make(chan struct{})
+	gomock.InOrder(
+		suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(fmt.Errorf("throttled")).Times(2),
+		suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+			close(done)
+			return nil
+		}),
+	)
+
+	bus := NewEventBus(func(o *Options) {
+		strict := true
+		o.Strict = &strict
+		o.Retry = &RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			Jitter:         0.5,
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloReply{Message: "Hello Cheddar"})
+	assert.Nil(suite.T(), err)
+
+	<-done
+	cancel()
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestRetryExhausted() {
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(fmt.Errorf("throttled")).Times(2)
+
+	bus := NewEventBus(func(o *Options) {
+		strict := true
+		o.Strict = &strict
+		o.Retry = &RetryPolicy{
+			MaxAttempts: 5,
+		}
+		o.MethodRetry = map[string]RetryPolicy{
+			"HelloWorld": {
+				MaxAttempts:    2,
+				InitialBackoff: time.Millisecond,
+			},
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errs := make(chan error)
+	go func() {
+		errs <- bus.Run(ctx, suite.service)
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloReply{Message: "Hello Cheddar"})
+	assert.Nil(suite.T(), err)
+	assert.EqualError(suite.T(), <-errs, "HelloWorld failed after 2 attempts: throttled")
+}
+
+func (suite *EventBusTestSuite) TestPermanentError() {
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(Permanent(fmt.Errorf("bad request")))
+
+	bus := NewEventBus(func(o *Options) {
+		strict := true
+		o.Strict = &strict
+		o.Retry = &RetryPolicy{
+			MaxAttempts: 3,
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errs := make(chan error)
+	go func() {
+		errs <- bus.Run(ctx, suite.service)
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloReply{Message: "Hello Cheddar"})
+	assert.Nil(suite.T(), err)
+	assert.EqualError(suite.T(), <-errs, "bad request")
+}
+
+func (suite *EventBusTestSuite) TestRetryJitterClamped() {
+	policy := RetryPolicy{InitialBackoff: time.Second, Jitter: 5}
+	for i := 0; i < 100; i++ {
+		backoff := policy.backoff(1)
+		assert.GreaterOrEqual(suite.T(), backoff, time.Duration(0))
+		assert.LessOrEqual(suite.T(), backoff, 2*time.Second)
+	}
+
+	policy.Jitter = -1
+	assert.Equal(suite.T(), time.Second, policy.backoff(1))
+}
@@ -0,0 +1,118 @@
+package simple
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *EventBusTestSuite) TestShutdownDrains() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handled := make(chan struct{})
+	suite.service.EXPECT().SayHello(HelloRequest{Name: "Cheddar"}).DoAndReturn(func(HelloRequest) (HelloReply, error) {
+		close(started)
+		<-release
+		return HelloReply{Message: "Hello Cheddar"}, nil
+	})
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		close(handled)
+		return nil
+	})
+
+	bus := NewEventBus()
+
+	errs := make(chan error)
+	go func() {
+		errs <- bus.Run(context.Background(), suite.service)
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	type result struct {
+		report ShutdownReport
+		err    error
+	}
+	shutdown := make(chan result)
+	go func() {
+		report, err := bus.Shutdown(ctx)
+		shutdown <- result{report, err}
+	}()
+
+	assert.Eventually(suite.T(), func() bool {
+		return bus.Publish(HelloRequest{Name: "Gouda"}) == ErrBusClosed
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	res := <-shutdown
+	assert.Nil(suite.T(), res.err)
+	assert.Empty(suite.T(), res.report.Abandoned)
+	<-handled
+	assert.Nil(suite.T(), <-errs)
+}
+
+func (suite *EventBusTestSuite) TestShutdownDeadline() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	bus := NewEventBus()
+
+	go func() {
+		assert.Nil(suite.T(), bus.Run(context.Background(), suite.service))
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloReply{Message: "Hello Cheddar"})
+	assert.Nil(suite.T(), err)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	report, err := bus.Shutdown(ctx)
+	assert.Equal(suite.T(), context.DeadlineExceeded, err)
+	assert.Len(suite.T(), report.Abandoned, 1)
+	assert.Equal(suite.T(), "HelloReply", report.Abandoned[0].Type)
+	assert.Equal(suite.T(), HelloReply{Message: "Hello Cheddar"}, report.Abandoned[0].Data)
+}
+
+func (suite *EventBusTestSuite) TestPublishAfterRun() {
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+	cancel()
+	<-done
+
+	assert.Equal(suite.T(), ErrBusClosed, bus.Publish(HelloRequest{Name: "Cheddar"}))
+}
+
+func (suite *EventBusTestSuite) TestBusClosedMarked() {
+	// transports recognize ErrBusClosed, wrapped or not, without importing the generated package
+	var closed interface{ BusClosed() bool }
+	assert.True(suite.T(), errors.As(fmt.Errorf("handling: %w", ErrBusClosed), &closed))
+	assert.True(suite.T(), closed.BusClosed())
+}
@@ -0,0 +1,98 @@
+package simple
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *EventBusTestSuite) TestTransport() {
+	done := make(chan struct{})
+	suite.service.EXPECT().SayHello(HelloRequest{Name: "Cheddar"}).Return(HelloReply{Message: "Hello Cheddar"}, nil)
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		close(done)
+		return nil
+	})
+
+	transport := NewMemoryTransport()
+	withTransport := func(o *Options) {
+		o.Transport = transport
+	}
+	publisher := NewEventBus(withTransport)
+	first := NewEventBus(withTransport)
+	second := NewEventBus(withTransport)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, bus := range []*EventBus{first, second} {
+		wg.Add(1)
+		go func(bus *EventBus) {
+			defer wg.Done()
+			assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+		}(bus)
+		bus.Ready()
+	}
+
+	c := make(chan Event, 1)
+	publisher.Subscribe("HelloRequest", c)
+
+	err := publisher.Publish(HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), HelloRequest{Name: "Cheddar"}, (<-c).Data)
+
+	<-done
+	cancel()
+	wg.Wait()
+}
+
+type keyedTransport struct {
+	*MemoryTransport
+	keys chan string
+}
+
+func (t keyedTransport) PublishKey(ctx context.Context, topic string, key string, data []byte) error {
+	t.keys <- key
+	return t.Publish(ctx, topic, data)
+}
+
+func (suite *EventBusTestSuite) TestPublishWithKey() {
+	transport := keyedTransport{MemoryTransport: NewMemoryTransport(), keys: make(chan string, 1)}
+	bus := NewEventBus(func(o *Options) {
+		o.Transport = transport
+	})
+
+	c := make(chan Event, 1)
+	bus.Subscribe("HelloRequest", c)
+
+	err := bus.PublishWithKey(context.Background(), "cheddar", HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "cheddar", <-transport.keys)
+	assert.Equal(suite.T(), "cheddar", (<-c).Key)
+}
+
+func (suite *EventBusTestSuite) TestJSONCodec() {
+	event := Event{
+		ID:            "1",
+		Time:          time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		CorrelationID: "2",
+		CausationID:   "3",
+		Headers:       map[string]string{"tenant": "cheddar"},
+		Key:           "cheddar",
+		Type:          "HelloReply",
+		Data:          HelloReply{Message: "Hello Cheddar"},
+	}
+
+	data, err := JSONCodec{}.Marshal(event)
+	assert.Nil(suite.T(), err)
+
+	decoded, err := JSONCodec{}.Unmarshal(data)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), event, decoded)
+
+	_, err = JSONCodec{}.Unmarshal([]byte(`{"type":"Unknown","data":{}}`))
+	assert.NotNil(suite.T(), err)
+}
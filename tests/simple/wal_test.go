@@ -0,0 +1,143 @@
+package simple
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *EventBusTestSuite) TestWALReplay() {
+	dir := suite.T().TempDir()
+	withWAL := func(o *Options) {
+		o.WAL = &WALOptions{Dir: dir}
+	}
+
+	failed := make(chan struct{})
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		close(failed)
+		return fmt.Errorf("crashed")
+	})
+
+	bus := NewEventBus(withWAL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+	err := bus.Publish(HelloReply{Message: "Hello Cheddar"})
+	assert.Nil(suite.T(), err)
+
+	<-failed
+	cancel()
+	wg.Wait()
+
+	handled := make(chan struct{})
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		close(handled)
+		return nil
+	})
+
+	restarted := NewEventBus(withWAL)
+	assert.Len(suite.T(), restarted.wal.unacknowledged(), 1)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), restarted.Run(ctx, suite.service))
+	}()
+
+	<-handled
+	_, err = restarted.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+
+	assert.Len(suite.T(), NewEventBus(withWAL).wal.unacknowledged(), 0)
+}
+
+func (suite *EventBusTestSuite) TestWALCompaction() {
+	dir := suite.T().TempDir()
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).Return(nil).Times(5)
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Gouda"}).Return(fmt.Errorf("crashed"))
+
+	bus := NewEventBus(func(o *Options) {
+		o.WAL = &WALOptions{
+			Dir:         dir,
+			Sync:        SyncNever,
+			SegmentSize: 1024,
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+	assert.Nil(suite.T(), bus.Publish(HelloReply{Message: "Hello Gouda"}))
+	for i := 0; i < 5; i++ {
+		assert.Nil(suite.T(), bus.Publish(HelloReply{Message: "Hello Cheddar"}))
+	}
+
+	_, err := bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), segments, 1)
+	assert.NotEqual(suite.T(), fmt.Sprintf("%020d.wal", 1), filepath.Base(segments[0]), "segment was not compacted")
+
+	unacknowledged := NewEventBus(func(o *Options) {
+		o.WAL = &WALOptions{Dir: dir}
+	}).wal.unacknowledged()
+	assert.Len(suite.T(), unacknowledged, 1)
+	assert.Equal(suite.T(), HelloReply{Message: "Hello Gouda"}, unacknowledged[0].event.Data)
+	assert.Equal(suite.T(), []string{"HelloWorld"}, unacknowledged[0].methods)
+}
+
+func (suite *EventBusTestSuite) TestWALTornRecord() {
+	dir := suite.T().TempDir()
+	bus := NewEventBus(func(o *Options) {
+		o.WAL = &WALOptions{Dir: dir}
+	})
+	assert.Nil(suite.T(), bus.Publish(HelloRequest{Name: "Cheddar"}))
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), segments, 1)
+
+	fout, err := os.OpenFile(segments[0], os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.Nil(suite.T(), err)
+	_, err = fout.WriteString(`{"event":"eyJp`)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), fout.Close())
+
+	restarted := NewEventBus(func(o *Options) {
+		o.WAL = &WALOptions{Dir: dir}
+	})
+	assert.Nil(suite.T(), restarted.walErr)
+
+	unacknowledged := restarted.wal.unacknowledged()
+	assert.Len(suite.T(), unacknowledged, 1)
+	assert.Equal(suite.T(), HelloRequest{Name: "Cheddar"}, unacknowledged[0].event.Data)
+	assert.Equal(suite.T(), []string{"SayHello"}, unacknowledged[0].methods)
+}
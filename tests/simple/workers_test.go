@@ -0,0 +1,119 @@
+package simple
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// concurrency records the most handlers running at once
+type concurrency struct {
+	lock    sync.Mutex
+	running int
+	max     int
+}
+
+func (c *concurrency) handle(hold time.Duration) {
+	c.lock.Lock()
+	c.running++
+	if c.running > c.max {
+		c.max = c.running
+	}
+	c.lock.Unlock()
+
+	time.Sleep(hold)
+
+	c.lock.Lock()
+	c.running--
+	c.lock.Unlock()
+}
+
+func (c *concurrency) Max() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.max
+}
+
+func (suite *EventBusTestSuite) runConcurrently(bus *EventBus, events int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+	for i := 0; i < events; i++ {
+		assert.Nil(suite.T(), bus.PublishAsync(HelloReply{Message: "Hello Cheddar"}).Wait(ctx))
+	}
+
+	_, err := bus.Shutdown(ctx)
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestWorkers() {
+	var c concurrency
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		c.handle(20 * time.Millisecond)
+		return nil
+	}).Times(6)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{BufferSize: 6}
+	})
+	suite.runConcurrently(bus, 6)
+	assert.Equal(suite.T(), 1, c.Max())
+}
+
+func (suite *EventBusTestSuite) TestMethodWorkers() {
+	var c concurrency
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		c.handle(50 * time.Millisecond)
+		return nil
+	}).Times(6)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{BufferSize: 6}
+		o.MethodWorkers = map[string]int{"HelloWorld": 3}
+	})
+	suite.runConcurrently(bus, 6)
+	assert.Equal(suite.T(), 3, c.Max())
+}
+
+func (suite *EventBusTestSuite) TestMaxConcurrency() {
+	var c concurrency
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		c.handle(20 * time.Millisecond)
+		return nil
+	}).Times(6)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{BufferSize: 6}
+		o.Workers = 4
+		o.MaxConcurrency = 2
+	})
+	suite.runConcurrently(bus, 6)
+	assert.Equal(suite.T(), 2, c.Max())
+}
+
+func (suite *EventBusTestSuite) TestInvalidWorkers() {
+	var c concurrency
+	suite.service.EXPECT().HelloWorld(HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(HelloReply) error {
+		c.handle(20 * time.Millisecond)
+		return nil
+	}).Times(4)
+
+	bus := NewEventBus(func(o *Options) {
+		o.Queue = QueueOptions{BufferSize: 4}
+		o.Workers = -2
+		o.MethodWorkers = map[string]int{"HelloWorld": 0}
+	})
+	suite.runConcurrently(bus, 4)
+	assert.Equal(suite.T(), 1, c.Max())
+}
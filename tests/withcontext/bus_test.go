@@ -0,0 +1,112 @@
+package withcontext
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type EventBusTestSuite struct {
+	suite.Suite
+	service *MockService
+}
+
+func (suite *EventBusTestSuite) SetupTest() {
+	suite.service = NewMockService(gomock.NewController(suite.T()))
+}
+
+func (suite *EventBusTestSuite) TestExample() {
+	gomock.InOrder(
+		suite.service.EXPECT().SayHello(gomock.Any(), HelloRequest{Name: "Cheddar"}).Return(HelloReply{Message: "Hello Cheddar"}, nil),
+		suite.service.EXPECT().HelloWorld(gomock.Any(), HelloReply{Message: "Hello Cheddar"}).Return(nil),
+	)
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := bus.Run(ctx, suite.service); err != nil {
+			panic(err)
+		}
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+	wg.Wait()
+}
+
+func (suite *EventBusTestSuite) TestCancellation() {
+	started := make(chan struct{})
+	suite.service.EXPECT().HelloWorld(gomock.Any(), HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(ctx context.Context, _ HelloReply) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := bus.Run(ctx, suite.service); err != nil {
+			panic(err)
+		}
+	}()
+
+	bus.Ready()
+
+	err := bus.Publish(HelloReply{Message: "Hello Cheddar"})
+	assert.Nil(suite.T(), err)
+
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		suite.T().Fatal("handler did not observe Run cancellation")
+	}
+}
+
+func (suite *EventBusTestSuite) TestTypedPublish() {
+	done := make(chan struct{})
+	suite.service.EXPECT().HelloWorld(gomock.Any(), HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(ctx context.Context, _ HelloReply) error {
+		event, ok := EventFromContext(ctx)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), "cheddar", event.CorrelationID)
+		close(done)
+		return nil
+	})
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go bus.Run(ctx, suite.service)
+	bus.Ready()
+
+	parent := context.WithValue(ctx, eventKey{}, Event{ID: "1", CorrelationID: "cheddar"})
+	err := bus.PublishHelloReply(parent, HelloReply{Message: "Hello Cheddar"})
+	assert.Nil(suite.T(), err)
+	<-done
+}
+
+func TestEventBusTestSuite(t *testing.T) {
+	suite.Run(t, new(EventBusTestSuite))
+}
@@ -0,0 +1,81 @@
+package withcontext
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *EventBusTestSuite) TestEnvelope() {
+	envelopes := make(chan Event, 2)
+	suite.service.EXPECT().SayHello(gomock.Any(), HelloRequest{Name: "Cheddar"}).DoAndReturn(func(ctx context.Context, _ HelloRequest) (HelloReply, error) {
+		event, ok := EventFromContext(ctx)
+		assert.True(suite.T(), ok)
+		envelopes <- event
+		return HelloReply{Message: "Hello Cheddar"}, nil
+	})
+	suite.service.EXPECT().HelloWorld(gomock.Any(), HelloReply{Message: "Hello Cheddar"}).DoAndReturn(func(ctx context.Context, _ HelloReply) error {
+		event, ok := EventFromContext(ctx)
+		assert.True(suite.T(), ok)
+		envelopes <- event
+		return nil
+	})
+
+	bus := NewEventBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(suite.T(), bus.Run(ctx, suite.service))
+	}()
+
+	bus.Ready()
+
+	_, ok := EventFromContext(ctx)
+	assert.False(suite.T(), ok)
+
+	err := bus.PublishEvent(ctx, Event{
+		CorrelationID: "trace-1",
+		Headers:       map[string]string{"tenant": "cheddar"},
+		Data:          HelloRequest{Name: "Cheddar"},
+	})
+	assert.Nil(suite.T(), err)
+
+	request := <-envelopes
+	reply := <-envelopes
+	cancel()
+	wg.Wait()
+
+	assert.NotEmpty(suite.T(), request.ID)
+	assert.False(suite.T(), request.Time.IsZero())
+	assert.Equal(suite.T(), "HelloRequest", request.Type)
+	assert.Equal(suite.T(), "trace-1", request.CorrelationID)
+	assert.Empty(suite.T(), request.CausationID)
+	assert.Equal(suite.T(), map[string]string{"tenant": "cheddar"}, request.Headers)
+
+	assert.NotEqual(suite.T(), request.ID, reply.ID)
+	assert.Equal(suite.T(), "HelloReply", reply.Type)
+	assert.Equal(suite.T(), "trace-1", reply.CorrelationID)
+	assert.Equal(suite.T(), request.ID, reply.CausationID)
+	assert.Equal(suite.T(), map[string]string{"tenant": "cheddar"}, reply.Headers)
+}
+
+func (suite *EventBusTestSuite) TestEnvelopeDefaults() {
+	bus := NewEventBus()
+	c := make(chan Event, 1)
+	bus.Subscribe("HelloRequest", c)
+
+	assert.Nil(suite.T(), bus.Publish(HelloRequest{Name: "Cheddar"}))
+	event := <-c
+	assert.NotEmpty(suite.T(), event.ID)
+	assert.Equal(suite.T(), event.ID, event.CorrelationID)
+	assert.Empty(suite.T(), event.CausationID)
+	assert.Empty(suite.T(), event.Headers)
+}
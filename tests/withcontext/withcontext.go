@@ -0,0 +1,4 @@
+package withcontext
+
+//go:generate go-event-bus-gen --in withcontext.proto --out bus.go --context
+//go:generate mockgen -source=bus.go -destination mocks.go -package withcontext
@@ -0,0 +1,16 @@
+module github.com/rc1405/go-event-bus-gen/transports/kafka
+
+go 1.23
+
+require (
+	github.com/segmentio/kafka-go v0.4.50
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
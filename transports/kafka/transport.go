@@ -0,0 +1,267 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Writer publishes messages to Kafka, satisfied by *kafka.Writer.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Reader consumes the messages of a topic as a member of a consumer group, satisfied by *kafka.Reader.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Options configures the Kafka Transport.
+type Options struct {
+	// TopicPrefix is prepended to every event type to build its topic, i.e. "events." publishes HelloRequest to
+	// "events.HelloRequest".
+	TopicPrefix string
+	// Writer overrides the writer built from the brokers.
+	Writer Writer
+	// NewReader overrides how readers are built from the brokers for a topic and consumer group.
+	NewReader func(topic string, group string) Reader
+	// RetryBackoff is how long to wait before handling a failed message again.  Defaults to 1 second.
+	RetryBackoff time.Duration
+	// MaxAttempts is how many times a message is handled before it is given up on, passed to OnDeadLetter and
+	// committed.  Defaults to 5, as do values below 1.
+	MaxAttempts int
+	// OnError is called whenever a message could not be fetched, handled, dead lettered or committed.
+	OnError func(msg kafka.Message, err error)
+	// OnDeadLetter is called with a message that failed MaxAttempts times and its last error, i.e. to copy it to a dead
+	// letter topic.  The message is committed once it returns nil, otherwise it is called again after RetryBackoff.
+	// Messages that are given up on are dropped when it is nil.
+	OnDeadLetter func(ctx context.Context, msg kafka.Message, err error) error
+}
+
+// Transport carries the events of generated EventBus instances over Kafka.  Each event type is published to its own
+// topic, and subscribers join a consumer group, committing each message only once it has been handled.
+type Transport struct {
+	writer       Writer
+	newReader    func(topic string, group string) Reader
+	prefix       string
+	backoff      time.Duration
+	maxAttempts  int
+	onError      func(msg kafka.Message, err error)
+	onDeadLetter func(ctx context.Context, msg kafka.Message, err error) error
+}
+
+/**
+ * New returns a Transport publishing and consuming with the given brokers.
+ * Parameters:
+ *   brokers: addresses of the Kafka brokers
+ *   opts: functions to modify the Options
+ * Returns:
+ *   *Transport
+ */
+func New(brokers []string, opts ...func(*Options)) *Transport {
+	options := Options{
+		RetryBackoff: time.Second,
+		MaxAttempts:  5,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.MaxAttempts < 1 {
+		options.MaxAttempts = 5
+	}
+
+	var writer Writer
+	switch options.Writer {
+	case nil:
+		writer = &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Balancer: &kafka.Hash{},
+		}
+	default:
+		writer = options.Writer
+	}
+
+	var newReader func(topic string, group string) Reader
+	switch options.NewReader {
+	case nil:
+		newReader = func(topic string, group string) Reader {
+			return kafka.NewReader(kafka.ReaderConfig{
+				Brokers: brokers,
+				GroupID: group,
+				Topic:   topic,
+			})
+		}
+	default:
+		newReader = options.NewReader
+	}
+
+	return &Transport{
+		writer:       writer,
+		newReader:    newReader,
+		prefix:       options.TopicPrefix,
+		backoff:      options.RetryBackoff,
+		maxAttempts:  options.MaxAttempts,
+		onError:      options.OnError,
+		onDeadLetter: options.OnDeadLetter,
+	}
+}
+
+/**
+ * Topic returns the topic events of a type are published to.
+ * Parameters:
+ *   eventType: event type name
+ * Returns:
+ *   string
+ */
+func (t *Transport) Topic(eventType string) string {
+	return t.prefix + eventType
+}
+
+/**
+ * Publish sends an encoded event to the topic of its type.
+ * Parameters:
+ *   ctx: context of the publish
+ *   topic: event type name
+ *   data: encoded event
+ * Returns:
+ *   error: error if the message could not be written
+ */
+func (t *Transport) Publish(ctx context.Context, topic string, data []byte) error {
+	return t.writer.WriteMessages(ctx, kafka.Message{Topic: t.Topic(topic), Value: data})
+}
+
+/**
+ * PublishKey sends an encoded event to the topic of its type, in the partition of key.
+ * Parameters:
+ *   ctx: context of the publish
+ *   topic: event type name
+ *   key: partition key
+ *   data: encoded event
+ * Returns:
+ *   error: error if the message could not be written
+ */
+func (t *Transport) PublishKey(ctx context.Context, topic string, key string, data []byte) error {
+	return t.writer.WriteMessages(ctx, kafka.Message{Topic: t.Topic(topic), Key: []byte(key), Value: data})
+}
+
+/**
+ * Subscribe joins the consumer group of the topic for a type, calling handler for each message until ctx is done.
+ * A message is committed once handler returns nil, otherwise it is handled again after RetryBackoff, up to
+ * MaxAttempts times, before it is passed to OnDeadLetter and committed.  A message whose handler returns the
+ * ErrBusClosed of a shutting down EventBus ends the subscription without being committed.
+ * Parameters:
+ *   ctx: context bounding the subscription
+ *   topic: event type name
+ *   group: consumer group name
+ *   handler: function handling each encoded event
+ * Returns:
+ *   error: always nil, errors while consuming are passed to OnError
+ */
+func (t *Transport) Subscribe(ctx context.Context, topic string, group string, handler func(context.Context, []byte) error) error {
+	reader := t.newReader(t.Topic(topic), group)
+
+	go func() {
+		defer reader.Close()
+		for {
+			msg, err := reader.FetchMessage(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				t.report(msg, err)
+				if !t.wait(ctx) {
+					return
+				}
+				continue
+			}
+
+			if !t.handle(ctx, msg, handler) {
+				return
+			}
+
+			if err := reader.CommitMessages(ctx, msg); err != nil && ctx.Err() == nil {
+				t.report(msg, err)
+			}
+		}
+	}()
+	return nil
+}
+
+// handle calls handler until it succeeds or has failed MaxAttempts times, when the message is dead lettered, returning
+// false if ctx is done or the EventBus shut down first
+func (t *Transport) handle(ctx context.Context, msg kafka.Message, handler func(context.Context, []byte) error) bool {
+	for attempt := 1; ; attempt++ {
+		err := handler(ctx, msg.Value)
+		if err == nil {
+			return true
+		}
+
+		// the EventBus is shutting down, leaving the message to be handled again once the group rebalances
+		if busClosed(err) {
+			return false
+		}
+
+		t.report(msg, err)
+		if attempt >= t.maxAttempts {
+			return t.deadLetter(ctx, msg, err)
+		}
+		if !t.wait(ctx) {
+			return false
+		}
+	}
+}
+
+// deadLetter passes msg to OnDeadLetter until it succeeds, returning false if ctx is done first
+func (t *Transport) deadLetter(ctx context.Context, msg kafka.Message, err error) bool {
+	if t.onDeadLetter == nil {
+		return true
+	}
+
+	for {
+		dlErr := t.onDeadLetter(ctx, msg, err)
+		if dlErr == nil {
+			return true
+		}
+
+		t.report(msg, dlErr)
+		if !t.wait(ctx) {
+			return false
+		}
+	}
+}
+
+// busClosed reports whether err is the ErrBusClosed of a generated EventBus, marked by its BusClosed method
+func busClosed(err error) bool {
+	var closed interface{ BusClosed() bool }
+	return errors.As(err, &closed) && closed.BusClosed()
+}
+
+func (t *Transport) wait(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(t.backoff):
+		return true
+	}
+}
+
+func (t *Transport) report(msg kafka.Message, err error) {
+	if t.onError != nil {
+		t.onError(msg, err)
+	}
+}
+
+/**
+ * Close flushes and closes the writer.
+ * Returns:
+ *   error: error if pending messages could not be written
+ */
+func (t *Transport) Close() error {
+	return t.writer.Close()
+}
@@ -0,0 +1,310 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeBroker is an in-process stand-in for Kafka, keeping a single partition per topic and the committed offset of each
+// consumer group.
+type fakeBroker struct {
+	lock      sync.Mutex
+	topics    map[string][]kafka.Message
+	fetched   map[string]int64
+	committed map[string]int64
+	closed    int
+	written   chan struct{}
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{
+		topics:    make(map[string][]kafka.Message),
+		fetched:   make(map[string]int64),
+		committed: make(map[string]int64),
+		written:   make(chan struct{}),
+	}
+}
+
+func (b *fakeBroker) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, msg := range msgs {
+		msg.Offset = int64(len(b.topics[msg.Topic]))
+		b.topics[msg.Topic] = append(b.topics[msg.Topic], msg)
+	}
+	close(b.written)
+	b.written = make(chan struct{})
+	return nil
+}
+
+func (b *fakeBroker) Close() error {
+	return nil
+}
+
+func (b *fakeBroker) Committed(topic string, group string) int64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.committed[group+"/"+topic]
+}
+
+func (b *fakeBroker) Closed() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.closed
+}
+
+func (b *fakeBroker) NewReader(topic string, group string) Reader {
+	return &fakeReader{broker: b, topic: topic, group: group}
+}
+
+type fakeReader struct {
+	broker *fakeBroker
+	topic  string
+	group  string
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	key := r.group + "/" + r.topic
+	for {
+		r.broker.lock.Lock()
+		offset := r.broker.fetched[key]
+		if offset < int64(len(r.broker.topics[r.topic])) {
+			r.broker.fetched[key]++
+			msg := r.broker.topics[r.topic][offset]
+			r.broker.lock.Unlock()
+			return msg, nil
+		}
+		written := r.broker.written
+		r.broker.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		case <-written:
+		}
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.broker.lock.Lock()
+	defer r.broker.lock.Unlock()
+	for _, msg := range msgs {
+		r.broker.committed[r.group+"/"+msg.Topic] = msg.Offset + 1
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	r.broker.lock.Lock()
+	defer r.broker.lock.Unlock()
+	r.broker.closed++
+	return nil
+}
+
+type TransportTestSuite struct {
+	suite.Suite
+	broker    *fakeBroker
+	transport *Transport
+	errs      chan error
+}
+
+func (suite *TransportTestSuite) SetupTest() {
+	suite.broker = newFakeBroker()
+	suite.errs = make(chan error, 10)
+	suite.transport = New(nil, func(o *Options) {
+		o.TopicPrefix = "events."
+		o.Writer = suite.broker
+		o.NewReader = suite.broker.NewReader
+		o.RetryBackoff = time.Millisecond
+		o.OnError = func(msg kafka.Message, err error) {
+			suite.errs <- err
+		}
+	})
+}
+
+func (suite *TransportTestSuite) TestPublishSubscribe() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(map[string]chan []byte)
+	for _, group := range []string{"SayHello", "Audit"} {
+		c := make(chan []byte, 1)
+		received[group] = c
+		err := suite.transport.Subscribe(ctx, "HelloRequest", group, func(ctx context.Context, data []byte) error {
+			c <- data
+			return nil
+		})
+		assert.Nil(suite.T(), err)
+	}
+
+	err := suite.transport.Publish(ctx, "HelloRequest", []byte(`{"type":"HelloRequest"}`))
+	assert.Nil(suite.T(), err)
+
+	for group, c := range received {
+		assert.Equal(suite.T(), []byte(`{"type":"HelloRequest"}`), <-c)
+		assert.Eventually(suite.T(), func() bool {
+			return suite.broker.Committed("events.HelloRequest", group) == 1
+		}, 5*time.Second, time.Millisecond)
+	}
+}
+
+func (suite *TransportTestSuite) TestPublishKey() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := suite.transport.PublishKey(ctx, "HelloRequest", "cheddar", []byte(`{}`))
+	assert.Nil(suite.T(), err)
+
+	msg, err := suite.broker.NewReader("events.HelloRequest", "SayHello").FetchMessage(ctx)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []byte("cheddar"), msg.Key)
+}
+
+func (suite *TransportTestSuite) TestCommitAfterSuccess() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	attempts := 0
+	done := make(chan struct{})
+	err := suite.transport.Subscribe(ctx, "HelloReply", "HelloWorld", func(ctx context.Context, data []byte) error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("throttled")
+		}
+
+		assert.Equal(suite.T(), int64(0), suite.broker.Committed("events.HelloReply", "HelloWorld"))
+		close(done)
+		return nil
+	})
+	assert.Nil(suite.T(), err)
+
+	err = suite.transport.Publish(ctx, "HelloReply", []byte(`{}`))
+	assert.Nil(suite.T(), err)
+
+	assert.EqualError(suite.T(), <-suite.errs, "throttled")
+	<-done
+	assert.Eventually(suite.T(), func() bool {
+		return suite.broker.Committed("events.HelloReply", "HelloWorld") == 1
+	}, 5*time.Second, time.Millisecond)
+}
+
+func (suite *TransportTestSuite) TestDeadLetterAfterMaxAttempts() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deadLetters := make(chan kafka.Message, 1)
+	transport := New(nil, func(o *Options) {
+		o.TopicPrefix = "events."
+		o.Writer = suite.broker
+		o.NewReader = suite.broker.NewReader
+		o.RetryBackoff = time.Millisecond
+		o.MaxAttempts = 3
+		o.OnError = func(msg kafka.Message, err error) {
+			suite.errs <- err
+		}
+		o.OnDeadLetter = func(ctx context.Context, msg kafka.Message, err error) error {
+			assert.EqualError(suite.T(), err, "poison")
+			assert.Equal(suite.T(), int64(0), suite.broker.Committed("events.HelloReply", "HelloWorld"))
+			deadLetters <- msg
+			return nil
+		}
+	})
+
+	attempts := 0
+	handled := make(chan []byte)
+	err := transport.Subscribe(ctx, "HelloReply", "HelloWorld", func(ctx context.Context, data []byte) error {
+		if string(data) == "poison" {
+			attempts++
+			return fmt.Errorf("poison")
+		}
+		handled <- data
+		return nil
+	})
+	assert.Nil(suite.T(), err)
+
+	assert.Nil(suite.T(), transport.Publish(ctx, "HelloReply", []byte("poison")))
+	assert.Nil(suite.T(), transport.Publish(ctx, "HelloReply", []byte(`{}`)))
+
+	msg := <-deadLetters
+	assert.Equal(suite.T(), []byte("poison"), msg.Value)
+	assert.Equal(suite.T(), 3, attempts)
+	assert.Len(suite.T(), suite.errs, 3)
+
+	// the partition moves on past the dead lettered message
+	assert.Equal(suite.T(), []byte(`{}`), <-handled)
+	assert.Eventually(suite.T(), func() bool {
+		return suite.broker.Committed("events.HelloReply", "HelloWorld") == 2
+	}, 5*time.Second, time.Millisecond)
+}
+
+// busClosedError stands in for the ErrBusClosed of a generated EventBus.
+type busClosedError struct{}
+
+func (busClosedError) Error() string {
+	return "event bus is closed"
+}
+
+func (busClosedError) BusClosed() bool {
+	return true
+}
+
+func (suite *TransportTestSuite) TestShutdownInFlight() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deadLettered := false
+	transport := New(nil, func(o *Options) {
+		o.Writer = suite.broker
+		o.NewReader = suite.broker.NewReader
+		o.RetryBackoff = time.Millisecond
+		o.MaxAttempts = 1
+		o.OnDeadLetter = func(ctx context.Context, msg kafka.Message, err error) error {
+			deadLettered = true
+			return nil
+		}
+	})
+
+	inFlight := make(chan struct{})
+	shutdown := make(chan struct{})
+	err := transport.Subscribe(ctx, "HelloReply", "HelloWorld", func(ctx context.Context, data []byte) error {
+		close(inFlight)
+		<-shutdown
+		return fmt.Errorf("handling: %w", busClosedError{})
+	})
+	assert.Nil(suite.T(), err)
+
+	assert.Nil(suite.T(), transport.Publish(ctx, "HelloReply", []byte(`{}`)))
+	<-inFlight
+	close(shutdown)
+
+	// the subscription ends with the message left for another member of the group
+	assert.Eventually(suite.T(), func() bool {
+		return suite.broker.Closed() == 1
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(suite.T(), int64(0), suite.broker.Committed("HelloReply", "HelloWorld"))
+	assert.False(suite.T(), deadLettered)
+}
+
+func (suite *TransportTestSuite) TestCloseReader() {
+	ctx, cancel := context.WithCancel(context.Background())
+	err := suite.transport.Subscribe(ctx, "HelloRequest", "SayHello", func(ctx context.Context, data []byte) error {
+		return nil
+	})
+	assert.Nil(suite.T(), err)
+
+	cancel()
+	assert.Eventually(suite.T(), func() bool {
+		return suite.broker.Closed() == 1
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestTransportTestSuite(t *testing.T) {
+	suite.Run(t, new(TransportTestSuite))
+}
@@ -0,0 +1,103 @@
+package bustest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	natstransport "github.com/rc1405/go-event-bus-gen/transports/nats"
+)
+
+type service struct {
+	replies chan HelloReply
+}
+
+func (s *service) SayHello(req HelloRequest) (HelloReply, error) {
+	return HelloReply{Message: "Hello " + req.Name}, nil
+}
+
+func (s *service) HelloWorld(reply HelloReply) error {
+	s.replies <- reply
+	return nil
+}
+
+type EventBusTestSuite struct {
+	suite.Suite
+	server *server.Server
+	conn   *nats.Conn
+}
+
+func (suite *EventBusTestSuite) SetupTest() {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	suite.server = natsserver.RunServer(&opts)
+
+	conn, err := nats.Connect(suite.server.ClientURL())
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	suite.conn = conn
+}
+
+func (suite *EventBusTestSuite) TearDownTest() {
+	suite.conn.Close()
+	suite.server.Shutdown()
+}
+
+func (suite *EventBusTestSuite) TestEventBus() {
+	transport := natstransport.New(suite.conn, func(o *natstransport.Options) {
+		o.SubjectPrefix = "events."
+	})
+	withTransport := func(o *Options) {
+		o.Transport = transport
+	}
+	publisher := NewEventBus(withTransport)
+	first := NewEventBus(withTransport)
+	second := NewEventBus(withTransport)
+	svc := &service{replies: make(chan HelloReply, 2)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, bus := range []*EventBus{first, second} {
+		wg.Add(1)
+		go func(bus *EventBus) {
+			defer wg.Done()
+			assert.Nil(suite.T(), bus.Run(ctx, svc))
+		}(bus)
+		bus.Ready()
+	}
+	// the subscriptions must reach the server before anything is published
+	assert.Nil(suite.T(), suite.conn.Flush())
+
+	err := publisher.Publish(HelloRequest{Name: "Cheddar"})
+	assert.Nil(suite.T(), err)
+
+	// the reply of SayHello is published over NATS too, and each event is handled by one bus of the queue group
+	select {
+	case reply := <-svc.replies:
+		assert.Equal(suite.T(), HelloReply{Message: "Hello Cheddar"}, reply)
+	case <-ctx.Done():
+		suite.T().Fatal("reply was not handled")
+	}
+	select {
+	case reply := <-svc.replies:
+		suite.T().Fatalf("reply %v was handled twice", reply)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	wg.Wait()
+}
+
+func TestEventBusTestSuite(t *testing.T) {
+	suite.Run(t, new(EventBusTestSuite))
+}
@@ -0,0 +1,4 @@
+// Package bustest runs a generated EventBus over the NATS Transport.
+package bustest
+
+//go:generate go-event-bus-gen --in hello.proto --out bus.go
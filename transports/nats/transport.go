@@ -0,0 +1,100 @@
+package nats
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Options configures the NATS Transport.
+type Options struct {
+	// SubjectPrefix is prepended to every event type to build its subject, i.e. "events." publishes HelloRequest to
+	// "events.HelloRequest".
+	SubjectPrefix string
+	// OnError is called when a message could not be handled.  Core NATS does not redeliver messages, so this is the
+	// last chance to see it.
+	OnError func(msg *nats.Msg, err error)
+}
+
+// Transport carries the events of generated EventBus instances over NATS.  Each event type is published to its own
+// subject, and subscribers of the same group share a queue group so replicas of a service split the work.
+type Transport struct {
+	conn    *nats.Conn
+	prefix  string
+	onError func(msg *nats.Msg, err error)
+}
+
+/**
+ * New returns a Transport publishing and subscribing over conn.
+ * Parameters:
+ *   conn: connection to the NATS server
+ *   opts: functions to modify the Options
+ * Returns:
+ *   *Transport
+ */
+func New(conn *nats.Conn, opts ...func(*Options)) *Transport {
+	options := Options{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Transport{
+		conn:    conn,
+		prefix:  options.SubjectPrefix,
+		onError: options.OnError,
+	}
+}
+
+/**
+ * Subject returns the subject events of a type are published to.
+ * Parameters:
+ *   topic: event type name
+ * Returns:
+ *   string
+ */
+func (t *Transport) Subject(topic string) string {
+	return t.prefix + topic
+}
+
+/**
+ * Publish sends an encoded event to the subject of its type.
+ * Parameters:
+ *   ctx: context of the publish
+ *   topic: event type name
+ *   data: encoded event
+ * Returns:
+ *   error: error if the message could not be published
+ */
+func (t *Transport) Publish(ctx context.Context, topic string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return t.conn.Publish(t.Subject(topic), data)
+}
+
+/**
+ * Subscribe joins the queue group of the subject for a type, calling handler for each message until ctx is done.
+ * Parameters:
+ *   ctx: context bounding the subscription
+ *   topic: event type name
+ *   group: queue group name
+ *   handler: function handling each encoded event
+ * Returns:
+ *   error: error if the subscription could not be created
+ */
+func (t *Transport) Subscribe(ctx context.Context, topic string, group string, handler func(context.Context, []byte) error) error {
+	sub, err := t.conn.QueueSubscribe(t.Subject(topic), group, func(msg *nats.Msg) {
+		if err := handler(ctx, msg.Data); err != nil && t.onError != nil {
+			t.onError(msg, err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		sub.Unsubscribe()
+	}()
+	return nil
+}
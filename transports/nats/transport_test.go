@@ -0,0 +1,143 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransportTestSuite struct {
+	suite.Suite
+	server *server.Server
+	conn   *nats.Conn
+}
+
+func (suite *TransportTestSuite) SetupTest() {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	suite.server = natsserver.RunServer(&opts)
+
+	conn, err := nats.Connect(suite.server.ClientURL())
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	suite.conn = conn
+}
+
+func (suite *TransportTestSuite) TearDownTest() {
+	suite.conn.Close()
+	suite.server.Shutdown()
+}
+
+func (suite *TransportTestSuite) TestPublishSubscribe() {
+	transport := New(suite.conn, func(o *Options) {
+		o.SubjectPrefix = "events."
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(chan []byte, 1)
+	err := transport.Subscribe(ctx, "HelloRequest", "SayHello", func(ctx context.Context, data []byte) error {
+		received <- data
+		return nil
+	})
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.conn.Flush())
+
+	raw, err := suite.conn.SubscribeSync("events.HelloRequest")
+	assert.Nil(suite.T(), err)
+
+	err = transport.Publish(ctx, "HelloRequest", []byte(`{"type":"HelloRequest"}`))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []byte(`{"type":"HelloRequest"}`), <-received)
+
+	msg, err := raw.NextMsg(time.Second)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "events.HelloRequest", msg.Subject)
+}
+
+func (suite *TransportTestSuite) TestQueueGroups() {
+	transport := New(suite.conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var lock sync.Mutex
+	counts := make(map[string]int)
+	var wg sync.WaitGroup
+	wg.Add(20)
+	subscribe := func(group string) {
+		err := transport.Subscribe(ctx, "HelloReply", group, func(ctx context.Context, data []byte) error {
+			lock.Lock()
+			counts[group]++
+			lock.Unlock()
+			wg.Done()
+			return nil
+		})
+		assert.Nil(suite.T(), err)
+	}
+
+	// Two replicas of HelloWorld share its messages, while Audit receives every message
+	subscribe("HelloWorld")
+	subscribe("HelloWorld")
+	subscribe("Audit")
+	assert.Nil(suite.T(), suite.conn.Flush())
+
+	for i := 0; i < 10; i++ {
+		assert.Nil(suite.T(), transport.Publish(ctx, "HelloReply", []byte(fmt.Sprint(i))))
+	}
+
+	wg.Wait()
+	assert.Equal(suite.T(), map[string]int{"HelloWorld": 10, "Audit": 10}, counts)
+}
+
+func (suite *TransportTestSuite) TestHandlerError() {
+	errs := make(chan error, 1)
+	transport := New(suite.conn, func(o *Options) {
+		o.OnError = func(msg *nats.Msg, err error) {
+			errs <- err
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := transport.Subscribe(ctx, "HelloRequest", "SayHello", func(ctx context.Context, data []byte) error {
+		return fmt.Errorf("throttled")
+	})
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.conn.Flush())
+
+	assert.Nil(suite.T(), transport.Publish(ctx, "HelloRequest", nil))
+	assert.EqualError(suite.T(), <-errs, "throttled")
+}
+
+func (suite *TransportTestSuite) TestUnsubscribe() {
+	transport := New(suite.conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := transport.Subscribe(ctx, "HelloRequest", "SayHello", func(ctx context.Context, data []byte) error {
+		return nil
+	})
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.conn.Flush())
+	assert.Equal(suite.T(), 1, suite.conn.NumSubscriptions())
+
+	cancel()
+	assert.Eventually(suite.T(), func() bool {
+		return suite.conn.NumSubscriptions() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTransportTestSuite(t *testing.T) {
+	suite.Run(t, new(TransportTestSuite))
+}
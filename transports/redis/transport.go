@@ -0,0 +1,218 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Options configures the Redis Streams Transport.
+type Options struct {
+	// StreamPrefix is prepended to every event type to build its stream key, i.e. "events:" publishes HelloRequest to
+	// "events:HelloRequest".
+	StreamPrefix string
+	// Consumer names this process within consumer groups.  Defaults to the hostname.
+	Consumer string
+	// MaxLen caps the length of each stream, trimming the oldest entries approximately.  Unlimited when 0.
+	MaxLen int64
+	// Count is the most entries read or reclaimed at once.  Defaults to 10.
+	Count int64
+	// Block is how long a read waits for new entries.  Defaults to 1 second.
+	Block time.Duration
+	// MinIdle is how long an entry stays pending, i.e. delivered to a consumer that crashed or failed to handle it,
+	// before another consumer reclaims it.  Defaults to 30 seconds.
+	MinIdle time.Duration
+	// ClaimInterval is how often pending entries are reclaimed.  Defaults to MinIdle.
+	ClaimInterval time.Duration
+	// OnError is called whenever entries could not be read, handled or acknowledged.
+	OnError func(stream string, err error)
+}
+
+// Transport carries the events of generated EventBus instances over Redis Streams.  Each event type is added to its
+// own stream, and subscribers read it as consumers of a consumer group, acknowledging each entry only once it has been
+// handled.
+type Transport struct {
+	client    redis.UniversalClient
+	options   Options
+	consumers atomic.Int64
+}
+
+/**
+ * New returns a Transport adding and reading entries with client.
+ * Parameters:
+ *   client: Redis client
+ *   opts: functions to modify the Options
+ * Returns:
+ *   *Transport
+ */
+func New(client redis.UniversalClient, opts ...func(*Options)) *Transport {
+	options := Options{
+		Count:   10,
+		Block:   time.Second,
+		MinIdle: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.Consumer == "" {
+		options.Consumer, _ = os.Hostname()
+	}
+	if options.ClaimInterval == 0 {
+		options.ClaimInterval = options.MinIdle
+	}
+
+	return &Transport{
+		client:  client,
+		options: options,
+	}
+}
+
+/**
+ * Stream returns the key of the stream events of a type are added to.
+ * Parameters:
+ *   topic: event type name
+ * Returns:
+ *   string
+ */
+func (t *Transport) Stream(topic string) string {
+	return t.options.StreamPrefix + topic
+}
+
+/**
+ * Publish adds an encoded event to the stream of its type.
+ * Parameters:
+ *   ctx: context of the publish
+ *   topic: event type name
+ *   data: encoded event
+ * Returns:
+ *   error: error if the entry could not be added
+ */
+func (t *Transport) Publish(ctx context.Context, topic string, data []byte) error {
+	return t.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: t.Stream(topic),
+		MaxLen: t.options.MaxLen,
+		Approx: t.options.MaxLen > 0,
+		Values: map[string]any{"data": data},
+	}).Err()
+}
+
+/**
+ * Subscribe joins the consumer group of the stream for a type, creating it if needed, and calls handler for each
+ * entry until ctx is done.  Entries are acknowledged once handler returns nil; the others stay pending until they are
+ * reclaimed after MinIdle.
+ * Parameters:
+ *   ctx: context bounding the subscription
+ *   topic: event type name
+ *   group: consumer group name
+ *   handler: function handling each encoded event
+ * Returns:
+ *   error: error if the consumer group could not be created
+ */
+func (t *Transport) Subscribe(ctx context.Context, topic string, group string, handler func(context.Context, []byte) error) error {
+	stream := t.Stream(topic)
+	err := t.client.XGroupCreateMkStream(ctx, stream, group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	consumer := fmt.Sprintf("%s-%d", t.options.Consumer, t.consumers.Add(1))
+	go func() {
+		var claimed time.Time
+		for ctx.Err() == nil {
+			if time.Since(claimed) >= t.options.ClaimInterval {
+				t.reclaim(ctx, stream, group, consumer, handler)
+				claimed = time.Now()
+			}
+
+			streams, err := t.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group:    group,
+				Consumer: consumer,
+				Streams:  []string{stream, ">"},
+				Count:    t.options.Count,
+				Block:    t.options.Block,
+			}).Result()
+			if err != nil {
+				if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+					t.report(stream, err)
+					t.wait(ctx)
+				}
+				continue
+			}
+
+			for _, s := range streams {
+				t.handle(ctx, stream, group, s.Messages, handler)
+			}
+		}
+	}()
+	return nil
+}
+
+// reclaim takes over and handles the entries of the group pending for longer than MinIdle
+func (t *Transport) reclaim(ctx context.Context, stream string, group string, consumer string, handler func(context.Context, []byte) error) {
+	start := "0-0"
+	for ctx.Err() == nil {
+		messages, next, err := t.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    group,
+			Consumer: consumer,
+			MinIdle:  t.options.MinIdle,
+			Start:    start,
+			Count:    t.options.Count,
+		}).Result()
+		if err != nil {
+			if ctx.Err() == nil {
+				t.report(stream, err)
+			}
+			return
+		}
+
+		t.handle(ctx, stream, group, messages, handler)
+		if next == "0-0" {
+			return
+		}
+		start = next
+	}
+}
+
+func (t *Transport) handle(ctx context.Context, stream string, group string, messages []redis.XMessage, handler func(context.Context, []byte) error) {
+	for _, msg := range messages {
+		data, ok := msg.Values["data"].(string)
+		if !ok {
+			t.report(stream, fmt.Errorf("entry %s has no data", msg.ID))
+			t.ack(ctx, stream, group, msg.ID)
+			continue
+		}
+
+		if err := handler(ctx, []byte(data)); err != nil {
+			t.report(stream, fmt.Errorf("entry %s: %w", msg.ID, err))
+			continue
+		}
+		t.ack(ctx, stream, group, msg.ID)
+	}
+}
+
+func (t *Transport) ack(ctx context.Context, stream string, group string, id string) {
+	if err := t.client.XAck(ctx, stream, group, id).Err(); err != nil && ctx.Err() == nil {
+		t.report(stream, err)
+	}
+}
+
+func (t *Transport) wait(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(t.options.Block):
+	}
+}
+
+func (t *Transport) report(stream string, err error) {
+	if t.options.OnError != nil {
+		t.options.OnError(stream, err)
+	}
+}
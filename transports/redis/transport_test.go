@@ -0,0 +1,174 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransportTestSuite struct {
+	suite.Suite
+	server    *miniredis.Miniredis
+	client    *redis.Client
+	transport *Transport
+}
+
+func (suite *TransportTestSuite) SetupTest() {
+	suite.server = miniredis.RunT(suite.T())
+	suite.client = redis.NewClient(&redis.Options{Addr: suite.server.Addr()})
+	suite.transport = New(suite.client, func(o *Options) {
+		o.StreamPrefix = "events:"
+		o.Consumer = "test"
+		o.Block = 10 * time.Millisecond
+		o.MinIdle = 50 * time.Millisecond
+		o.ClaimInterval = 10 * time.Millisecond
+	})
+}
+
+func (suite *TransportTestSuite) TearDownTest() {
+	suite.client.Close()
+}
+
+func (suite *TransportTestSuite) pending(stream string, group string) int64 {
+	pending, err := suite.client.XPending(context.Background(), stream, group).Result()
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	return pending.Count
+}
+
+func (suite *TransportTestSuite) TestPublishSubscribe() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(map[string]chan []byte)
+	for _, group := range []string{"SayHello", "Audit"} {
+		c := make(chan []byte, 1)
+		received[group] = c
+		err := suite.transport.Subscribe(ctx, "HelloRequest", group, func(ctx context.Context, data []byte) error {
+			c <- data
+			return nil
+		})
+		assert.Nil(suite.T(), err)
+	}
+
+	err := suite.transport.Publish(ctx, "HelloRequest", []byte(`{"type":"HelloRequest"}`))
+	assert.Nil(suite.T(), err)
+
+	for group, c := range received {
+		assert.Equal(suite.T(), []byte(`{"type":"HelloRequest"}`), <-c)
+		assert.Eventually(suite.T(), func() bool {
+			return suite.pending("events:HelloRequest", group) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+}
+
+func (suite *TransportTestSuite) TestSharedGroup() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(chan []byte, 10)
+	for i := 0; i < 2; i++ {
+		err := suite.transport.Subscribe(ctx, "HelloReply", "HelloWorld", func(ctx context.Context, data []byte) error {
+			received <- data
+			return nil
+		})
+		assert.Nil(suite.T(), err)
+	}
+
+	for i := 0; i < 10; i++ {
+		assert.Nil(suite.T(), suite.transport.Publish(ctx, "HelloReply", []byte(fmt.Sprint(i))))
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		seen[string(<-received)] = true
+	}
+	assert.Len(suite.T(), seen, 10)
+
+	select {
+	case data := <-received:
+		suite.T().Fatalf("received %s twice", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func (suite *TransportTestSuite) TestRedeliverFailed() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	attempts := 0
+	done := make(chan struct{})
+	err := suite.transport.Subscribe(ctx, "HelloReply", "HelloWorld", func(ctx context.Context, data []byte) error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("throttled")
+		}
+
+		assert.Equal(suite.T(), int64(1), suite.pending("events:HelloReply", "HelloWorld"))
+		close(done)
+		return nil
+	})
+	assert.Nil(suite.T(), err)
+
+	err = suite.transport.Publish(ctx, "HelloReply", []byte(`{}`))
+	assert.Nil(suite.T(), err)
+
+	<-done
+	assert.Eventually(suite.T(), func() bool {
+		return suite.pending("events:HelloReply", "HelloWorld") == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (suite *TransportTestSuite) TestReclaimCrashed() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A consumer reads an entry and crashes before acknowledging it
+	err := suite.client.XGroupCreateMkStream(ctx, "events:HelloRequest", "SayHello", "$").Err()
+	assert.Nil(suite.T(), err)
+	err = suite.transport.Publish(ctx, "HelloRequest", []byte(`{"type":"HelloRequest"}`))
+	assert.Nil(suite.T(), err)
+	_, err = suite.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    "SayHello",
+		Consumer: "crashed",
+		Streams:  []string{"events:HelloRequest", ">"},
+	}).Result()
+	assert.Nil(suite.T(), err)
+
+	received := make(chan []byte, 1)
+	err = suite.transport.Subscribe(ctx, "HelloRequest", "SayHello", func(ctx context.Context, data []byte) error {
+		received <- data
+		return nil
+	})
+	assert.Nil(suite.T(), err)
+
+	assert.Equal(suite.T(), []byte(`{"type":"HelloRequest"}`), <-received)
+	assert.Eventually(suite.T(), func() bool {
+		return suite.pending("events:HelloRequest", "SayHello") == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (suite *TransportTestSuite) TestMaxLen() {
+	transport := New(suite.client, func(o *Options) {
+		o.MaxLen = 2
+	})
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(suite.T(), transport.Publish(context.Background(), "HelloRequest", []byte(fmt.Sprint(i))))
+	}
+
+	length, err := suite.client.XLen(context.Background(), "HelloRequest").Result()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), length)
+}
+
+func TestTransportTestSuite(t *testing.T) {
+	suite.Run(t, new(TransportTestSuite))
+}